@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/spf13/cobra"
+
+	"bewallet/pkg/keystore"
+)
+
+var (
+	backupPassword     string
+	backupPasswordFrom string
+	conflict           string
+)
+
+func backupCMD() *cobra.Command {
+	c := &cobra.Command{
+		Use:   SubCMDBackup,
+		Short: "将全部账户备份为单个加密文件（不含已归档账户）",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return backup()
+		},
+	}
+	c.Flags().StringVarP(&output, "output", "o", "", "备份文件")
+	c.Flags().StringVar(&backupPasswordFrom, "backup-password-from", "", "备份文件口令来源"+sourceUsage)
+	return c
+}
+
+func restoreCMD() *cobra.Command {
+	c := &cobra.Command{
+		Use:   SubCMDRestore + " <file>",
+		Short: "从备份文件恢复账户",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return restore(args[0])
+		},
+	}
+	c.Flags().StringVar(&backupPasswordFrom, "backup-password-from", "", "备份文件口令来源"+sourceUsage)
+	c.Flags().StringVar(&conflict, "conflict", string(keystore.ConflictSkip), "账户重名时的处理方式：skip、overwrite、rename")
+	return c
+}
+
+func backup() error {
+	if len(output) == 0 {
+		return errors.New("缺少备份文件，请通过 -o 指定")
+	}
+	err := readNewSecret(&backupPassword, backupPasswordFrom, "请设置备份文件口令: ")
+	if err != nil {
+		return err
+	}
+	ks, err := getKeyStore()
+	if err != nil {
+		return err
+	}
+	data, err := keystore.Backup(ks, backupPassword)
+	if err != nil {
+		return err
+	}
+	err = ioutil.WriteFile(output, data, keystore.FilePerm)
+	if err != nil {
+		return err
+	}
+	fmt.Println("备份完成:", output)
+	return nil
+}
+
+func restore(file string) error {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	err = readSecret(&backupPassword, backupPasswordFrom, "请输入备份文件口令: ")
+	if err != nil {
+		return err
+	}
+	ks, err := getKeyStore()
+	if err != nil {
+		return err
+	}
+	result, err := keystore.Restore(ks, data, backupPassword, keystore.ConflictMode(conflict))
+	if result != nil {
+		for _, n := range result.Restored {
+			fmt.Println("  已恢复:", n)
+		}
+		for _, n := range result.Skipped {
+			fmt.Println("  已跳过:", n)
+		}
+		for from, to := range result.Renamed {
+			fmt.Printf("  已改名: %s -> %s\n", from, to)
+		}
+	}
+	return err
+}
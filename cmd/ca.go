@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"bewallet/pkg/fabca"
+)
+
+var (
+	caURL   string
+	caName  string
+	caTLS   string
+	caAttrs []string
+
+	enrollID         string
+	enrollSecret     string
+	enrollSecretFrom string
+
+	regType           string
+	regAffiliation    string
+	regMaxEnrollments int
+)
+
+func caFlags(c *cobra.Command) {
+	c.Flags().StringVarP(&name, "name", "n", "", "账户名称")
+	c.Flags().StringVar(&network, "network", "", "网络名称")
+	c.Flags().StringVar(&caURL, "ca-url", "", "Fabric CA 地址，如 https://ca.org1.example.com:7054")
+	c.Flags().StringVar(&caName, "ca-name", "", "CA 名称")
+	c.Flags().StringVar(&caTLS, "ca-tls", "", "校验 Fabric CA TLS 证书的根证书文件")
+}
+
+func enrollCMD() *cobra.Command {
+	c := &cobra.Command{
+		Use:   SubCMDEnroll,
+		Short: "使用钱包私钥向 Fabric CA 登记，获取身份证书",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return enroll()
+		},
+	}
+	caFlags(c)
+	c.Flags().StringVar(&mspID, "msp-id", "", "MSP ID")
+	c.Flags().StringVar(&enrollID, "enroll-id", "", "登记 ID")
+	c.Flags().StringVar(&enrollSecret, "enroll-secret", "", "登记口令")
+	c.Flags().StringVar(&enrollSecretFrom, "enroll-secret-from", "", "登记口令来源"+sourceUsage)
+	c.Flags().StringArrayVar(&caAttrs, "attr", nil, "申请写入证书的属性，可多次指定，格式为 name 或 name:opt（可选属性）")
+	return c
+}
+
+func reenrollCMD() *cobra.Command {
+	c := &cobra.Command{
+		Use:   SubCMDReenroll,
+		Short: "使用当前证书向 Fabric CA 重新登记，用于证书续期",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return reenroll()
+		},
+	}
+	caFlags(c)
+	c.Flags().StringArrayVar(&caAttrs, "attr", nil, "申请写入证书的属性，可多次指定，格式为 name 或 name:opt（可选属性）")
+	return c
+}
+
+func registerCMD() *cobra.Command {
+	c := &cobra.Command{
+		Use:   SubCMDRegister + " <id>",
+		Short: "以账户身份作为管理员在 Fabric CA 注册新身份",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return register(args[0])
+		},
+	}
+	caFlags(c)
+	c.Flags().StringVar(&regType, "type", "client", "身份类型")
+	c.Flags().StringVar(&regAffiliation, "affiliation", "", "身份所属机构")
+	c.Flags().StringVar(&enrollSecret, "secret", "", "登记口令，默认由 CA 生成")
+	c.Flags().IntVar(&regMaxEnrollments, "max-enrollments", 0, "最大登记次数，默认使用 CA 配置")
+	c.Flags().StringArrayVar(&caAttrs, "attr", nil, "身份属性，可多次指定，格式为 name=value，追加 :ecert 表示写入证书")
+	return c
+}
+
+func getCAClient() (*fabca.Client, error) {
+	if len(caURL) == 0 {
+		return nil, errors.New("缺少 Fabric CA 地址，请通过 --ca-url 指定")
+	}
+	opts := []fabca.Option{fabca.WithCAName(caName)}
+	if len(caTLS) != 0 {
+		cert, err := ioutil.ReadFile(caTLS)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, fabca.WithTLSCACerts(cert))
+	}
+	return fabca.NewClient(caURL, opts...)
+}
+
+func attrRequests() []fabca.AttributeRequest {
+	reqs := []fabca.AttributeRequest{}
+	for _, a := range caAttrs {
+		n := strings.TrimSuffix(a, ":opt")
+		reqs = append(reqs, fabca.AttributeRequest{Name: n, Optional: n != a})
+	}
+	return reqs
+}
+
+func enroll() error {
+	if len(mspID) == 0 {
+		return errors.New("缺少 MSP ID，请通过 --msp-id 指定")
+	}
+	err := readSecret(&enrollSecret, enrollSecretFrom, "请输入登记口令: ")
+	if err != nil {
+		return err
+	}
+	client, err := getCAClient()
+	if err != nil {
+		return err
+	}
+	w, err := loadWallet()
+	if err != nil {
+		return err
+	}
+	fw, err := w.Enroll(client, network, mspID, enrollID, enrollSecret, attrRequests()...)
+	if err != nil {
+		return err
+	}
+	fmt.Println("登记成功！")
+	fmt.Println("  网络名称:", fw.FabMSP.Network)
+	fmt.Println("  MSP ID:", fw.OrgMSP)
+	return nil
+}
+
+func reenroll() error {
+	client, err := getCAClient()
+	if err != nil {
+		return err
+	}
+	fw, err := loadFabWallet()
+	if err != nil {
+		return err
+	}
+	err = fw.Reenroll(client, attrRequests()...)
+	if err != nil {
+		return err
+	}
+	fmt.Println("重新登记成功！")
+	return nil
+}
+
+func register(id string) error {
+	client, err := getCAClient()
+	if err != nil {
+		return err
+	}
+	fw, err := loadFabWallet()
+	if err != nil {
+		return err
+	}
+	req := &fabca.RegistrationRequest{
+		Name:           id,
+		Type:           regType,
+		Secret:         enrollSecret,
+		MaxEnrollments: regMaxEnrollments,
+		Affiliation:    regAffiliation,
+	}
+	for _, a := range caAttrs {
+		kv := strings.SplitN(a, "=", 2)
+		if len(kv) != 2 {
+			return errors.Errorf("属性 %s 格式错误，应为 name=value", a)
+		}
+		value := strings.TrimSuffix(kv[1], ":ecert")
+		req.Attributes = append(req.Attributes, fabca.Attribute{Name: kv[0], Value: value, ECert: value != kv[1]})
+	}
+	secret, err := fw.Register(client, req)
+	if err != nil {
+		return err
+	}
+	fmt.Println("注册成功！")
+	fmt.Println("  登记 ID:", id)
+	fmt.Println("  登记口令:", secret)
+	return nil
+}
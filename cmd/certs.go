@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"bewallet/pkg/wallet"
+)
+
+var within time.Duration
+
+func certsCMD() *cobra.Command {
+	c := &cobra.Command{
+		Use:   SubCMDCerts,
+		Short: "列出即将到期或已过期的身份证书",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return certs()
+		},
+	}
+	c.Flags().DurationVar(&within, "within", 30*24*time.Hour, "列出该时长内到期的证书")
+	return c
+}
+
+func certs() error {
+	ks, err := getKeyStore()
+	if err != nil {
+		return err
+	}
+	m, err := wallet.NewManager(ks)
+	if err != nil {
+		return err
+	}
+	list := m.ExpiringCerts(within)
+	if len(list) == 0 {
+		fmt.Println("没有即将到期的证书")
+		return nil
+	}
+	now := time.Now()
+	for _, ce := range list {
+		switch {
+		case ce.Err != nil:
+			fmt.Printf("无效\t%s\t%s\t%s\t%v\n", ce.Name, ce.Network, ce.Addr, ce.Err)
+		case ce.Expired(now):
+			fmt.Printf("已过期\t%s\t%s\t%s\t%s\n", ce.Name, ce.Network, ce.Addr, ce.NotAfter.Local().Format(time.RFC3339))
+		default:
+			fmt.Printf("即将到期\t%s\t%s\t%s\t%s\n", ce.Name, ce.Network, ce.Addr, ce.NotAfter.Local().Format(time.RFC3339))
+		}
+	}
+	return nil
+}
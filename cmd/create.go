@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/text/unicode/norm"
+
+	"bewallet/pkg/wallet"
+)
+
+// 创建钱包时要求用户确认的助记词单词数及尝试次数
+const (
+	confirmWords    = 3
+	confirmAttempts = 3
+)
+
+var (
+	words       int
+	showOnce    bool
+	skipConfirm bool
+)
+
+func createCMD() *cobra.Command {
+	c := &cobra.Command{
+		Use:   SubCMDCreate,
+		Short: "创建钱包",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return create()
+		},
+	}
+	c.Flags().StringVarP(&name, "name", "n", "", "账户名称")
+	c.Flags().StringVar(&path, "path", wallet.DefaultPath, "密钥派生路径")
+	c.Flags().StringVar(&language, "language", string(wallet.DefaultLanguage), "助记词语言")
+	c.Flags().IntVar(&words, "words", wallet.DefaultWordCount, "助记词单词数量（12、15、18、21、24）")
+	c.Flags().StringVar(&passphraseFrom, "passphrase-from", "", "助记词口令（可选，不会被保存）来源"+sourceUsage)
+	c.Flags().BoolVar(&showOnce, "show-once", false, "不保存助记词，助记词仅展示这一次")
+	c.Flags().BoolVar(&skipConfirm, "skip-confirm", false, "跳过助记词确认")
+	return c
+}
+
+func create() error {
+	err := readSecret(&passphrase, passphraseFrom, "请输入助记词口令（可选，直接回车跳过）: ")
+	if err != nil {
+		return err
+	}
+	ks, err := getKeyStore()
+	if err != nil {
+		return err
+	}
+	opts := []wallet.Option{
+		wallet.WithPath(path),
+		wallet.WithLanguage(wallet.Language(language)),
+		wallet.WithWordCount(words),
+		wallet.WithPassphrase(passphrase),
+	}
+	if showOnce {
+		opts = append(opts, wallet.WithShowOnce())
+	}
+	w, err := wallet.CreateWallet(ks, name, opts...)
+	if err != nil {
+		return err
+	}
+	m, err := w.RevealMnemonic(password)
+	if err != nil {
+		return err
+	}
+	fmt.Println("钱包创建成功！")
+	fmt.Println("  钱包助记词:", m)
+	fmt.Println("  钱包地址:", w.Address())
+	fmt.Println("  派生路径:", w.Path())
+	if showOnce {
+		fmt.Println("  注意: 助记词不会被保存，请立即抄写并妥善保管")
+	}
+	if skipConfirm {
+		return nil
+	}
+	err = confirmMnemonic(m)
+	if err == nil {
+		fmt.Println("助记词确认成功！")
+		return nil
+	}
+	if !showOnce {
+		// 钱包已保存，确认失败不影响创建结果，仅提示用户
+		fmt.Fprintf(os.Stderr, "警告: %v，可稍后通过 %s --reveal 再次查看助记词\n", err, SubCMDShow)
+		return nil
+	}
+	// 助记词未保存且用户未能正确记录，删除新建账户以免留下无法恢复的账户
+	if derr := ks.Delete(accountName(w)); derr != nil {
+		return fmt.Errorf("%v，删除新建账户失败: %v", err, derr)
+	}
+	return fmt.Errorf("%v，已删除新建账户", err)
+}
+
+// accountName 钱包保存时使用的账户名称，未指定名称时为钱包地址
+func accountName(w *wallet.Wallet) string {
+	if len(name) != 0 {
+		return name
+	}
+	return w.Address()
+}
+
+// confirmMnemonic 随机抽取助记词中的若干单词要求用户输入，确认用户已正确记录
+func confirmMnemonic(mnemonic string) error {
+	list := strings.Fields(mnemonic)
+	positions, err := pickPositions(len(list), confirmWords)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < confirmAttempts; i++ {
+		ok := true
+		for _, p := range positions {
+			input, err := prompt(fmt.Sprintf("请输入助记词的第 %d 个单词: ", p+1))
+			if err != nil {
+				return err
+			}
+			if norm.NFKD.String(input) != list[p] {
+				ok = false
+			}
+		}
+		if ok {
+			return nil
+		}
+		fmt.Println("输入的单词不正确，请重试")
+	}
+	return errors.New("助记词确认失败")
+}
+
+// pickPositions 从 [0, n) 中随机选取 k 个不重复的位置并升序返回
+func pickPositions(n, k int) ([]int, error) {
+	if k > n {
+		k = n
+	}
+	picked := make(map[int]bool, k)
+	for len(picked) < k {
+		v, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+		if err != nil {
+			return nil, err
+		}
+		picked[int(v.Int64())] = true
+	}
+	positions := make([]int, 0, k)
+	for p := range picked {
+		positions = append(positions, p)
+	}
+	sort.Ints(positions)
+	return positions, nil
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"bewallet/pkg/keystore"
+	"bewallet/pkg/wallet"
+)
+
+var (
+	csrOpts  wallet.CSROpts
+	csrAttrs []string
+	csrDER   bool
+)
+
+func csrCMD() *cobra.Command {
+	c := &cobra.Command{
+		Use:   SubCMDCSR,
+		Short: "生成账户的证书请求，用于向 CA 申请身份证书",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return csr()
+		},
+	}
+	c.Flags().StringVarP(&name, "name", "n", "", "账户名称")
+	c.Flags().StringVar(&network, "network", "", "网络名称，组织默认取自该网络身份")
+	c.Flags().StringArrayVar(&csrOpts.OUs, "ou", nil, "组织单位，可多次指定，默认为 client")
+	c.Flags().StringVar(&csrOpts.Org, "org", "", "组织")
+	c.Flags().StringVar(&csrOpts.CNTemplate, "cn", wallet.DefaultCNTemplate, "通用名模板，支持 {address}、{name}、{org}、{network} 占位符")
+	c.Flags().StringVar(&csrOpts.Country, "country", "", "国家")
+	c.Flags().StringVar(&csrOpts.Province, "province", "", "省份")
+	c.Flags().StringVar(&csrOpts.Locality, "locality", "", "城市")
+	c.Flags().StringArrayVar(&sanDNS, "dns", nil, "DNS SAN，可多次指定")
+	c.Flags().StringArrayVar(&sanIP, "ip", nil, "IP SAN，可多次指定")
+	c.Flags().StringArrayVar(&sanEmail, "email", nil, "Email SAN，可多次指定")
+	c.Flags().StringArrayVar(&csrAttrs, "attr", nil, "Fabric 证书属性，可多次指定，格式为 name=value")
+	c.Flags().BoolVar(&csrDER, "der", false, "输出 DER 格式，默认为 PEM")
+	c.Flags().StringVarP(&output, "output", "o", "", "证书请求文件，默认输出到标准输出")
+	return c
+}
+
+func csr() error {
+	w, err := loadWallet()
+	if err != nil {
+		return err
+	}
+	nets, err := wallet.LoadFabNet(w.KeyStore, name)
+	if err != nil {
+		return err
+	}
+	fw := &wallet.FabWallet{Wallet: *w}
+	if len(network) == 0 && len(nets) == 1 {
+		for n := range nets {
+			network = n
+		}
+	}
+	if len(network) != 0 {
+		fabnet, ok := nets[network]
+		if !ok {
+			return errors.Errorf("账户没有网络 %s 的身份", network)
+		}
+		fw.FabNet = *fabnet
+	}
+	csrOpts.DNSNames = sanDNS
+	csrOpts.EmailAddresses = sanEmail
+	csrOpts.IPAddresses, err = parseIPs(sanIP)
+	if err != nil {
+		return err
+	}
+	for _, attr := range csrAttrs {
+		kv := strings.SplitN(attr, "=", 2)
+		if len(kv) != 2 || len(kv[0]) == 0 {
+			return errors.Errorf("证书属性 %s 格式错误，应为 name=value", attr)
+		}
+		if csrOpts.Attrs == nil {
+			csrOpts.Attrs = make(map[string]string)
+		}
+		csrOpts.Attrs[kv[0]] = kv[1]
+	}
+
+	var data []byte
+	if csrDER {
+		data, err = fw.CertRequest(&csrOpts)
+	} else {
+		data, err = fw.CertRequestPEM(&csrOpts)
+	}
+	if err != nil {
+		return err
+	}
+	if len(output) == 0 {
+		if csrDER {
+			return errors.New("DER 格式请通过 -o 指定输出文件")
+		}
+		fmt.Print(string(data))
+		return nil
+	}
+	return ioutil.WriteFile(output, data, keystore.FilePerm)
+}
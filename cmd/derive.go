@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"bewallet/pkg/wallet"
+)
+
+var (
+	index   uint32
+	newName string
+)
+
+func deriveCMD() *cobra.Command {
+	c := &cobra.Command{
+		Use:   SubCMDDerive,
+		Short: "使用已有钱包的助记词派生新账户",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return derive()
+		},
+	}
+	c.Flags().StringVarP(&name, "name", "n", "", "已有账户名称")
+	c.Flags().Uint32VarP(&index, "index", "i", 0, "派生账户序号")
+	c.Flags().StringVar(&newName, "new-name", "", "派生账户名称，默认为账户地址")
+	c.Flags().StringVar(&passphraseFrom, "passphrase-from", "", "助记词口令来源（创建账户时使用了口令则必须提供）"+sourceUsage)
+	return c
+}
+
+func derive() error {
+	w, err := loadWallet()
+	if err != nil {
+		return err
+	}
+	if w.HasPassphrase() {
+		err = readSecret(&passphrase, passphraseFrom, "请输入助记词口令: ")
+		if err != nil {
+			return err
+		}
+	}
+	d, err := w.Derive(newName, index, wallet.WithPassphrase(passphrase))
+	if err != nil {
+		return err
+	}
+	fmt.Println("账户派生成功！")
+	fmt.Println("  钱包地址:", d.Address())
+	fmt.Println("  派生路径:", d.Path())
+	return nil
+}
@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"bewallet/pkg/keystore"
+	"bewallet/pkg/wallet"
+)
+
+var (
+	caOpts wallet.DevCAOpts
+
+	certUsage string
+	certOUs   []string
+	sanDNS    []string
+	sanIP     []string
+	sanEmail  []string
+)
+
+func caInitCMD() *cobra.Command {
+	c := &cobra.Command{
+		Use:   SubCMDCAInit,
+		Short: "以账户私钥生成自签名根证书，作为本地开发网络的 CA",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return caInit()
+		},
+	}
+	c.Flags().StringVarP(&name, "name", "n", "", "CA 账户名称")
+	c.Flags().StringVar(&network, "network", "", "网络名称")
+	c.Flags().StringVar(&mspID, "msp-id", "", "MSP ID")
+	c.Flags().StringVar(&caOpts.Org, "org", "", "组织")
+	c.Flags().StringVar(&caOpts.CommonName, "cn", "", "根证书通用名，默认为 ca.<组织>")
+	c.Flags().StringVar(&caOpts.Country, "country", "", "国家")
+	c.Flags().StringVar(&caOpts.Province, "province", "", "省份")
+	c.Flags().StringVar(&caOpts.Locality, "locality", "", "城市")
+	c.Flags().StringVar(&caOpts.OrgUnit, "ou", "", "组织单位")
+	c.Flags().StringVarP(&output, "output", "o", "", "同时生成组织 MSP 目录")
+	return c
+}
+
+func caIssueCMD() *cobra.Command {
+	c := &cobra.Command{
+		Use:   SubCMDCAIssue + " <csr>",
+		Short: "使用开发 CA 为证书请求签发证书",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return caIssue(args[0])
+		},
+	}
+	issueFlags(c)
+	c.Flags().StringVar(&certUsage, "usage", wallet.CertUsageClient, "证书用途：client 或 tls")
+	c.Flags().StringArrayVar(&sanDNS, "dns", nil, "DNS SAN，可多次指定")
+	c.Flags().StringArrayVar(&sanIP, "ip", nil, "IP SAN，可多次指定")
+	c.Flags().StringArrayVar(&sanEmail, "email", nil, "Email SAN，可多次指定")
+	c.Flags().StringVarP(&output, "output", "o", "", "证书文件，默认输出到标准输出")
+	return c
+}
+
+func caEnrollCMD() *cobra.Command {
+	c := &cobra.Command{
+		Use:   SubCMDCAEnroll + " <account>",
+		Short: "使用开发 CA 为同一目录下的账户签发身份证书",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return caEnroll(args[0])
+		},
+	}
+	issueFlags(c)
+	c.Flags().StringVarP(&output, "output", "o", "", "同时导出账户 MSP 目录")
+	return c
+}
+
+func issueFlags(c *cobra.Command) {
+	c.Flags().StringVarP(&name, "name", "n", "", "CA 账户名称")
+	c.Flags().StringVar(&network, "network", "", "CA 网络名称，CA 账户只有一个网络身份时可省略")
+	c.Flags().StringArrayVar(&certOUs, "ou", nil, "证书 OU，覆盖证书请求中的 OU，如 client、peer、admin、orderer")
+}
+
+func caInit() error {
+	w, err := loadWallet()
+	if err != nil {
+		return err
+	}
+	ca, err := w.NewDevCA(network, mspID, &caOpts)
+	if err != nil {
+		return err
+	}
+	fmt.Println("CA 根证书生成成功！")
+	if len(output) != 0 {
+		err = ca.WriteOrgMSP(output)
+		if err != nil {
+			return err
+		}
+		fmt.Println("  组织 MSP 目录:", output)
+	}
+	return nil
+}
+
+func loadDevCA() (*wallet.DevCA, error) {
+	fw, err := loadFabWallet()
+	if err != nil {
+		return nil, err
+	}
+	return wallet.LoadDevCA(fw)
+}
+
+func caIssue(file string) error {
+	csr, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	ca, err := loadDevCA()
+	if err != nil {
+		return err
+	}
+	opts := &wallet.IssueOpts{
+		Usage:          certUsage,
+		OUs:            certOUs,
+		DNSNames:       sanDNS,
+		EmailAddresses: sanEmail,
+	}
+	opts.IPAddresses, err = parseIPs(sanIP)
+	if err != nil {
+		return err
+	}
+	cert, err := ca.Issue(csr, opts)
+	if err != nil {
+		return err
+	}
+	if len(output) == 0 {
+		fmt.Print(string(cert))
+		return nil
+	}
+	return ioutil.WriteFile(output, cert, keystore.FilePerm)
+}
+
+func caEnroll(account string) error {
+	ca, err := loadDevCA()
+	if err != nil {
+		return err
+	}
+	w, err := wallet.LoadWallet(ca.KeyStore(), account)
+	if err != nil {
+		return errors.WithMessagef(err, "加载账户 %s 失败", account)
+	}
+	fw, err := ca.Enroll(w, &wallet.IssueOpts{OUs: certOUs})
+	if err != nil {
+		return err
+	}
+	fmt.Println("证书签发成功！")
+	fmt.Println("  账户名称:", account)
+	fmt.Println("  网络名称:", fw.FabMSP.Network)
+	if len(output) == 0 {
+		return nil
+	}
+	err = fw.ExportMSP(output, password)
+	if err != nil {
+		return err
+	}
+	err = wallet.WriteNodeOUConfig(output)
+	if err != nil {
+		return err
+	}
+	fmt.Println("  MSP 目录:", output)
+	return nil
+}
+
+func parseIPs(list []string) ([]net.IP, error) {
+	var ips []net.IP
+	for _, s := range list {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return nil, errors.Errorf("IP 地址 %s 格式错误", s)
+		}
+		ips = append(ips, ip)
+	}
+	return ips, nil
+}
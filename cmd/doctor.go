@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"bewallet/pkg/keystore"
+)
+
+var fix bool
+
+func doctorCMD() *cobra.Command {
+	c := &cobra.Command{
+		Use:   SubCMDDoctor,
+		Short: "检查账户缓存目录中目录和文件的权限",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return doctor()
+		},
+	}
+	c.Flags().BoolVar(&fix, "fix", false, "修复权限不安全的目录和文件")
+	return c
+}
+
+func doctor() error {
+	dir, err := getBaseDir()
+	if err != nil {
+		return err
+	}
+	issues, err := keystore.Doctor(dir, fix)
+	if err != nil {
+		return err
+	}
+	if len(issues) == 0 {
+		fmt.Println("未发现权限问题")
+		return nil
+	}
+	unfixed := 0
+	for _, issue := range issues {
+		fmt.Println(" ", issue)
+		if !issue.Fixed {
+			unfixed++
+		}
+	}
+	if !fix {
+		return fmt.Errorf("发现 %d 处权限问题，可使用 --fix 修复", len(issues))
+	}
+	if unfixed != 0 {
+		return fmt.Errorf("%d 处问题无法自动修复，请手动处理", unfixed)
+	}
+	return nil
+}
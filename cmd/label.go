@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"bewallet/pkg/wallet"
+)
+
+var unlabel []string
+
+func labelCMD() *cobra.Command {
+	c := &cobra.Command{
+		Use:   SubCMDLabel + " <account> [key=value...]",
+		Short: "设置或删除账户标签，账户可以是名称或地址，不指定标签时列出已有标签",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return label(args[0], args[1:])
+		},
+	}
+	c.Flags().StringArrayVar(&unlabel, "delete", nil, "删除的标签，可多次指定")
+	return c
+}
+
+func label(account string, pairs []string) error {
+	labels := make(map[string]string)
+	for _, pair := range pairs {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || len(kv[0]) == 0 || len(kv[1]) == 0 {
+			return errors.Errorf("标签 %s 格式错误，应为 key=value", pair)
+		}
+		labels[kv[0]] = kv[1]
+	}
+	for _, k := range unlabel {
+		labels[k] = ""
+	}
+	ks, err := getKeyStore()
+	if err != nil {
+		return err
+	}
+	m, err := wallet.NewManager(ks)
+	if err != nil {
+		return err
+	}
+	if len(labels) != 0 {
+		err = m.SetLabels(account, labels)
+		if err != nil {
+			return err
+		}
+	}
+	acc := m.Account(account)
+	if acc == nil {
+		return errors.Errorf("账户 %s 不存在", account)
+	}
+	keys := make([]string, 0, len(acc.Labels))
+	for k := range acc.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Printf("%s=%s\n", k, acc.Labels[k])
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"bewallet/pkg/wallet"
+)
+
+func listCMD() *cobra.Command {
+	return &cobra.Command{
+		Use:   SubCMDList,
+		Short: "列出全部钱包账户",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return list()
+		},
+	}
+}
+
+func list() error {
+	ks, err := getKeyStore()
+	if err != nil {
+		return err
+	}
+	m, err := wallet.NewManager(ks)
+	if err != nil {
+		return err
+	}
+	skipped := m.Skipped()
+	names := make([]string, 0, len(skipped))
+	for n := range skipped {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		fmt.Fprintf(os.Stderr, "警告: 已跳过账户 %s: %v\n", n, skipped[n])
+	}
+	for _, acc := range m.Accounts() {
+		labels := make([]string, 0, len(acc.Labels))
+		for k, v := range acc.Labels {
+			labels = append(labels, k+"="+v)
+		}
+		sort.Strings(labels)
+		created := "-"
+		if !acc.Created.IsZero() {
+			created = acc.Created.Local().Format("2006-01-02 15:04:05")
+		}
+		fmt.Printf("%s\t%s\t%s\t%s\n", acc.Addr, acc.Name, created, strings.Join(labels, ","))
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var yes bool
+
+func deleteCMD() *cobra.Command {
+	c := &cobra.Command{
+		Use:   SubCMDDelete,
+		Short: "删除账户（密钥文件覆写后删除，不可恢复）",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return deleteAccount()
+		},
+	}
+	c.Flags().StringVarP(&name, "name", "n", "", "账户名称")
+	c.Flags().BoolVarP(&yes, "yes", "y", false, "确认删除")
+	return c
+}
+
+func renameCMD() *cobra.Command {
+	c := &cobra.Command{
+		Use:   SubCMDRename,
+		Short: "账户改名",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return renameAccount()
+		},
+	}
+	c.Flags().StringVarP(&name, "name", "n", "", "账户名称")
+	c.Flags().StringVar(&newName, "new-name", "", "新账户名称")
+	return c
+}
+
+func archiveCMD() *cobra.Command {
+	c := &cobra.Command{
+		Use:   SubCMDArchive,
+		Short: "归档账户，归档后不再出现在账户列表中",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return archiveAccount()
+		},
+	}
+	c.Flags().StringVarP(&name, "name", "n", "", "账户名称")
+	return c
+}
+
+func deleteAccount() error {
+	if !yes {
+		return errors.New("删除账户不可恢复，请使用 --yes 确认")
+	}
+	if len(name) == 0 {
+		return errors.New("缺少账户名称，请通过 -n 指定")
+	}
+	ks, err := getKeyStore()
+	if err != nil {
+		return err
+	}
+	err = ks.Delete(name)
+	if err != nil {
+		return err
+	}
+	fmt.Println("账户已删除:", name)
+	return nil
+}
+
+func renameAccount() error {
+	if len(name) == 0 || len(newName) == 0 {
+		return errors.New("缺少账户名称，请通过 -n 和 --new-name 指定")
+	}
+	ks, err := getKeyStore()
+	if err != nil {
+		return err
+	}
+	err = ks.Rename(name, newName)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("账户 %s 已改名为 %s\n", name, newName)
+	return nil
+}
+
+func archiveAccount() error {
+	if len(name) == 0 {
+		return errors.New("缺少账户名称，请通过 -n 指定")
+	}
+	ks, err := getKeyStore()
+	if err != nil {
+		return err
+	}
+	err = ks.Archive(name)
+	if err != nil {
+		return err
+	}
+	fmt.Println("账户已归档:", name)
+	return nil
+}
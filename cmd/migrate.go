@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func migrateCMD() *cobra.Command {
+	return &cobra.Command{
+		Use:   SubCMDMigrate,
+		Short: "将密钥缓存目录中早期格式的加密文件重新加密为当前格式",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return migrate()
+		},
+	}
+}
+
+func migrate() error {
+	ks, err := getKeyStore()
+	if err != nil {
+		return err
+	}
+	n, err := ks.Migrate()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("迁移完成，共重新加密 %d 个文件\n", n)
+	return nil
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"bewallet/pkg/wallet"
+)
+
+var (
+	mspID   string
+	network string
+)
+
+func importMSPCMD() *cobra.Command {
+	c := &cobra.Command{
+		Use:   SubCMDImportMSP + " <dir>",
+		Short: "从 Fabric MSP 目录（signcerts、keystore）导入身份",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return importMSP(args[0])
+		},
+	}
+	c.Flags().StringVarP(&name, "name", "n", "", "账户名称，默认为账户地址")
+	c.Flags().StringVar(&mspID, "msp-id", "", "MSP ID")
+	c.Flags().StringVar(&network, "network", "", "网络名称，默认为 MSP ID")
+	c.Flags().StringVar(&keyPasswordFrom, "key-password-from", "", "私钥口令来源（私钥为加密 PEM 时需要）"+sourceUsage)
+	return c
+}
+
+func exportMSPCMD() *cobra.Command {
+	c := &cobra.Command{
+		Use:   SubCMDExportMSP + " <dir>",
+		Short: "按 Fabric MSP 目录结构导出身份",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return exportMSP(args[0])
+		},
+	}
+	c.Flags().StringVarP(&name, "name", "n", "", "账户名称")
+	c.Flags().StringVar(&network, "network", "", "网络名称，账户只有一个网络身份时可省略")
+	return c
+}
+
+func importMSP(dir string) error {
+	if len(mspID) == 0 {
+		return errors.New("缺少 MSP ID，请通过 --msp-id 指定")
+	}
+	err := readSecret(&keyPassword, keyPasswordFrom, "请输入私钥口令（私钥未加密时直接回车）: ")
+	if err != nil {
+		return err
+	}
+	ks, err := getKeyStore()
+	if err != nil {
+		return err
+	}
+	fw, err := wallet.ImportMSP(ks, name, dir, mspID, network, []byte(keyPassword))
+	if err != nil {
+		return err
+	}
+	fmt.Println("身份导入成功！")
+	fmt.Println("  钱包地址:", fw.Address())
+	fmt.Println("  网络名称:", fw.FabMSP.Network)
+	fmt.Println("  MSP ID:", fw.OrgMSP)
+	return nil
+}
+
+func exportMSP(dir string) error {
+	fw, err := loadFabWallet()
+	if err != nil {
+		return err
+	}
+	err = fw.ExportMSP(dir, password)
+	if err != nil {
+		return err
+	}
+	fmt.Println("身份导出成功:", dir)
+	return nil
+}
+
+// loadFabWallet 加载账户在 --network 指定网络中的身份，账户只有一个网络时可省略 --network
+func loadFabWallet() (*wallet.FabWallet, error) {
+	w, err := loadWallet()
+	if err != nil {
+		return nil, err
+	}
+	nets, err := wallet.LoadFabNet(w.KeyStore, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(network) == 0 {
+		if len(nets) != 1 {
+			return nil, errors.Errorf("账户有 %d 个网络身份，请通过 --network 指定", len(nets))
+		}
+		for n := range nets {
+			network = n
+		}
+	}
+	fabnet, ok := nets[network]
+	if !ok {
+		return nil, errors.Errorf("账户没有网络 %s 的身份", network)
+	}
+	return &wallet.FabWallet{
+		Wallet: *w,
+		FabNet: *fabnet,
+	}, nil
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"bewallet/pkg/wallet"
+)
+
+var removeNetwork string
+
+func networksCMD() *cobra.Command {
+	c := &cobra.Command{
+		Use:   SubCMDNetworks + " <account>",
+		Short: "列出账户的网络及节点，账户可以是名称或地址",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return networks(args[0])
+		},
+	}
+	c.Flags().StringVar(&removeNetwork, "remove", "", "删除网络及其中的身份")
+	return c
+}
+
+func networks(account string) error {
+	ks, err := getKeyStore()
+	if err != nil {
+		return err
+	}
+	m, err := wallet.NewManager(ks)
+	if err != nil {
+		return err
+	}
+	if len(removeNetwork) != 0 {
+		err = m.RemoveNetwork(account, removeNetwork)
+		if err != nil {
+			return err
+		}
+		fmt.Println("网络删除成功！")
+		return nil
+	}
+	list, err := m.ListNetworks(account)
+	if err != nil {
+		return err
+	}
+	for _, n := range list {
+		fmt.Println(n.Name)
+		for _, p := range n.Peers {
+			fmt.Printf("  peer\t%s\t%s\n", p.Address, p.ServerOverride)
+		}
+		for _, o := range n.Orderers {
+			fmt.Printf("  orderer\t%s\t%s\n", o.Address, o.ServerOverride)
+		}
+		for _, ch := range n.Channels {
+			fmt.Printf("  channel\t%s\n", ch)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	newPassword     string
+	newPasswordFrom string
+)
+
+func passwdCMD() *cobra.Command {
+	c := &cobra.Command{
+		Use:   SubCMDPasswd,
+		Short: "修改账户缓存目录口令",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return passwd()
+		},
+	}
+	c.Flags().StringVar(&newPasswordFrom, "new-password-from", "", "新口令来源"+sourceUsage)
+	return c
+}
+
+func passwd() error {
+	ks, err := getKeyStore()
+	if err != nil {
+		return err
+	}
+	err = readNewSecret(&newPassword, newPasswordFrom, "请输入新口令: ")
+	if err != nil {
+		return err
+	}
+	err = ks.ChangePassword(password, newPassword)
+	if err != nil {
+		return err
+	}
+	fmt.Println("口令修改成功！")
+	return nil
+}
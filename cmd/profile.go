@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/spf13/cobra"
+
+	"bewallet/pkg/keystore"
+	"bewallet/pkg/wallet"
+)
+
+var (
+	profileChannel string
+	profileJSON    bool
+)
+
+func importProfileCMD() *cobra.Command {
+	c := &cobra.Command{
+		Use:   SubCMDImportProfile + " <profile>",
+		Short: "导入 Fabric 连接配置（YAML 或 JSON）为账户的网络",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return importProfile(args[0])
+		},
+	}
+	c.Flags().StringVarP(&name, "name", "n", "", "账户名称或地址")
+	c.Flags().StringVar(&network, "network", "", "网络名称，默认为连接配置名称")
+	c.Flags().StringVar(&profileChannel, "channel", "", "仅导入该通道的节点")
+	return c
+}
+
+func exportProfileCMD() *cobra.Command {
+	c := &cobra.Command{
+		Use:   SubCMDExportProfile,
+		Short: "将账户的网络导出为 Fabric 连接配置",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return exportProfile()
+		},
+	}
+	c.Flags().StringVarP(&name, "name", "n", "", "账户名称或地址")
+	c.Flags().StringVar(&network, "network", "", "网络名称")
+	c.Flags().BoolVar(&profileJSON, "json", false, "输出 JSON 格式，默认为 YAML")
+	c.Flags().StringVarP(&output, "output", "o", "", "连接配置文件，默认输出到标准输出")
+	return c
+}
+
+func importProfile(file string) error {
+	cp, err := wallet.LoadConnectionProfile(file)
+	if err != nil {
+		return err
+	}
+	ks, err := getKeyStore()
+	if err != nil {
+		return err
+	}
+	m, err := wallet.NewManager(ks)
+	if err != nil {
+		return err
+	}
+	fabnet, err := m.ImportProfile(name, cp, network, profileChannel)
+	if err != nil {
+		return err
+	}
+	fmt.Println("连接配置导入成功！")
+	fmt.Println("  网络名称:", fabnet.Network.Name)
+	fmt.Println("  peer 节点:", len(fabnet.Peers))
+	fmt.Println("  orderer 节点:", len(fabnet.Orderers))
+	return nil
+}
+
+func exportProfile() error {
+	ks, err := getKeyStore()
+	if err != nil {
+		return err
+	}
+	m, err := wallet.NewManager(ks)
+	if err != nil {
+		return err
+	}
+	cp, err := m.ExportProfile(name, network)
+	if err != nil {
+		return err
+	}
+	data, err := cp.Marshal(profileJSON)
+	if err != nil {
+		return err
+	}
+	if len(output) == 0 {
+		fmt.Print(string(data))
+		return nil
+	}
+	return ioutil.WriteFile(output, data, keystore.FilePerm)
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/spf13/cobra"
+)
+
+var output string
+
+func exportPubkeyCMD() *cobra.Command {
+	c := &cobra.Command{
+		Use:   SubCMDExportPubkey,
+		Short: "导出钱包公钥（PEM 格式）",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return exportPubkey()
+		},
+	}
+	c.Flags().StringVarP(&name, "name", "n", "", "账户名称")
+	c.Flags().StringVarP(&output, "output", "o", "", "输出文件，默认输出到标准输出")
+	return c
+}
+
+func exportPubkey() error {
+	w, err := loadWallet()
+	if err != nil {
+		return err
+	}
+	der, err := x509.MarshalPKIXPublicKey(w.PublicKey())
+	if err != nil {
+		return err
+	}
+	raw := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	if len(output) == 0 {
+		fmt.Print(string(raw))
+		return nil
+	}
+	return ioutil.WriteFile(output, raw, 0644)
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"bewallet/pkg/wallet"
+)
+
+var (
+	keyVersion string
+	shares     []string
+)
+
+func recoverCMD() *cobra.Command {
+	c := &cobra.Command{
+		Use:   SubCMDRecover,
+		Short: "通过助记词恢复钱包",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return recoverWallet()
+		},
+	}
+	c.Flags().StringVarP(&name, "name", "n", "", "账户名称")
+	c.Flags().StringVar(&mnemonicFrom, "mnemonic-from", "", "助记词来源"+sourceUsage)
+	c.Flags().StringVar(&path, "path", wallet.DefaultPath, "密钥派生路径")
+	c.Flags().StringVar(&keyVersion, "key-version", wallet.KeyVersionSLIP10, "密钥派生版本，恢复早期钱包时可指定 legacy、legacy-go1.20 或 legacy-go1.20-shift")
+	c.Flags().StringVar(&language, "language", "", "助记词语言，默认自动识别")
+	c.Flags().StringVar(&passphraseFrom, "passphrase-from", "", "助记词口令来源"+sourceUsage)
+	c.Flags().StringArrayVar(&shares, "share", nil, "助记词份额，可多次指定，使用份额恢复时忽略 --mnemonic-from、--path、--key-version 及 --language")
+	return c
+}
+
+func recoverWallet() error {
+	if len(shares) == 0 {
+		err := readSecret(&mnemonic, mnemonicFrom, "请输入助记词: ")
+		if err != nil {
+			return err
+		}
+	}
+	err := readSecret(&passphrase, passphraseFrom, "请输入助记词口令（未使用时直接回车）: ")
+	if err != nil {
+		return err
+	}
+	if len(mnemonic) == 0 && len(shares) == 0 {
+		return errors.New("缺少助记词，请交互输入或通过 --mnemonic-from、--share 指定")
+	}
+	ks, err := getKeyStore()
+	if err != nil {
+		return err
+	}
+	var w *wallet.Wallet
+	if len(shares) != 0 {
+		w, err = wallet.RecoverWalletFromShares(ks, name, shares, wallet.WithPassphrase(passphrase))
+	} else {
+		w, err = wallet.RecoverWallet(ks, name, mnemonic,
+			wallet.WithPath(path),
+			wallet.WithKeyVersion(keyVersion),
+			wallet.WithLanguage(wallet.Language(language)),
+			wallet.WithPassphrase(passphrase),
+		)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Println("钱包恢复成功！")
+	fmt.Println("  钱包地址:", w.Address())
+	return nil
+}
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+	"golang.org/x/term"
+)
+
+// 新口令强度要求：长度不少于 minPasswordLen 且包含大写字母、小写字母、数字、其他字符中的至少
+// minPasswordClasses 类，或长度不少于 minPassphraseLen（由多个单词组成的长口令）
+const (
+	minPasswordLen     = 8
+	minPasswordClasses = 3
+	minPassphraseLen   = 16
+)
+
+// 口令类参数来源前缀，用于 --xxx-from 参数
+const (
+	sourceEnv  = "env:"
+	sourceFile = "file:"
+	sourceFD   = "fd:"
+)
+
+// sourceUsage 口令类参数来源的说明
+const sourceUsage = "，格式为 env:变量名、file:文件路径 或 fd:文件描述符"
+
+// readSecret 获取口令类输入，依次尝试：已有的值、from 指定的来源、终端交互输入（不回显）。
+// 标准输入不是终端时不会提示，value 保持为空
+func readSecret(value *string, from, msg string) error {
+	if len(*value) != 0 {
+		return nil
+	}
+	if len(from) != 0 {
+		v, err := readSecretFrom(from)
+		if err != nil {
+			return err
+		}
+		*value = v
+		return nil
+	}
+	if !isTerminal() {
+		return nil
+	}
+	v, err := promptSecret(msg)
+	if err != nil {
+		return err
+	}
+	*value = v
+	return nil
+}
+
+// readNewSecret 获取新口令，终端交互输入时要求输入两次，口令需满足强度要求。
+// 标准输入不是终端且未指定来源，或来源中的口令为空时返回错误
+func readNewSecret(value *string, from, msg string) error {
+	if len(*value) == 0 && len(from) == 0 && isTerminal() {
+		v, err := promptSecret(msg)
+		if err != nil {
+			return err
+		}
+		if err = checkPasswordStrength(v); err != nil {
+			return err
+		}
+		again, err := promptSecret("请再次输入: ")
+		if err != nil {
+			return err
+		}
+		if again != v {
+			return errors.New("两次输入的口令不一致")
+		}
+		*value = v
+		return nil
+	}
+	err := readSecret(value, from, msg)
+	if err != nil {
+		return err
+	}
+	if len(*value) == 0 {
+		return errors.New("缺少口令，标准输入不是终端时请通过对应的 --xxx-from 参数指定")
+	}
+	return checkPasswordStrength(*value)
+}
+
+// readSecretFrom 从 env:NAME、file:PATH 或 fd:N 读取口令，去掉末尾的换行符
+func readSecretFrom(from string) (string, error) {
+	var data []byte
+	var err error
+	switch {
+	case strings.HasPrefix(from, sourceEnv):
+		v, ok := os.LookupEnv(strings.TrimPrefix(from, sourceEnv))
+		if !ok {
+			return "", errors.Errorf("环境变量 %s 未设置", strings.TrimPrefix(from, sourceEnv))
+		}
+		return v, nil
+	case strings.HasPrefix(from, sourceFile):
+		data, err = ioutil.ReadFile(strings.TrimPrefix(from, sourceFile))
+	case strings.HasPrefix(from, sourceFD):
+		fd, perr := strconv.Atoi(strings.TrimPrefix(from, sourceFD))
+		if perr != nil || fd < 0 {
+			return "", errors.Errorf("文件描述符 %s 非法", strings.TrimPrefix(from, sourceFD))
+		}
+		f := os.Stdin
+		if fd != 0 {
+			f = os.NewFile(uintptr(fd), "fd"+strconv.Itoa(fd))
+			if f == nil {
+				return "", errors.Errorf("文件描述符 %d 无效", fd)
+			}
+			// 标准输入后续可能还要读取，不能关闭
+			defer f.Close()
+		}
+		data, err = ioutil.ReadAll(f)
+	default:
+		return "", errors.Errorf("无法识别的来源 %s%s", from, sourceUsage)
+	}
+	if err != nil {
+		return "", errors.Wrapf(err, "读取 %s 失败", from)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+func isTerminal() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// promptSecret 输出提示并读取一行输入，标准输入为终端时不回显
+func promptSecret(msg string) (string, error) {
+	if !isTerminal() {
+		return prompt(msg)
+	}
+	fmt.Fprint(os.Stderr, msg)
+	data, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", errors.Wrap(err, "读取输入失败")
+	}
+	return string(data), nil
+}
+
+// checkPasswordStrength 检查新口令强度
+func checkPasswordStrength(pw string) error {
+	n := len([]rune(pw))
+	if n >= minPassphraseLen {
+		return nil
+	}
+	if n < minPasswordLen {
+		return errors.Errorf("口令长度至少为 %d 个字符", minPasswordLen)
+	}
+	var upper, lower, digit, other int
+	for _, r := range pw {
+		switch {
+		case unicode.IsUpper(r):
+			upper = 1
+		case unicode.IsLower(r):
+			lower = 1
+		case unicode.IsDigit(r):
+			digit = 1
+		default:
+			other = 1
+		}
+	}
+	if upper+lower+digit+other < minPasswordClasses {
+		return errors.Errorf("口令强度不足，需包含大写字母、小写字母、数字、其他字符中的至少 %d 类，或长度不少于 %d 个字符",
+			minPasswordClasses, minPassphraseLen)
+	}
+	return nil
+}
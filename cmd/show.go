@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"bewallet/pkg/wallet"
+)
+
+var reveal bool
+
+func showCMD() *cobra.Command {
+	c := &cobra.Command{
+		Use:   SubCMDShow,
+		Short: "加载并展示钱包信息",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return show()
+		},
+	}
+	c.Flags().StringVarP(&name, "name", "n", "", "账户名称")
+	c.Flags().BoolVar(&reveal, "reveal", false, "同时展示助记词")
+	return c
+}
+
+func show() error {
+	// 账户口令已通过来源提供时直接用于校验，否则要求再次交互输入
+	supplied := len(passwordFrom) != 0
+	w, err := loadWallet()
+	if err != nil {
+		return err
+	}
+	fmt.Println("  账户名称:", name)
+	fmt.Println("  钱包地址:", w.Address())
+	fmt.Println("  派生路径:", w.Path())
+	fmt.Println("  派生版本:", w.KeyVersion())
+	fmt.Println("  助记词语言:", w.Language())
+	if w.HasPassphrase() {
+		fmt.Println("  助记词口令: 已使用")
+	}
+	if !w.Recoverable() {
+		fmt.Println("  警告: 该账户私钥无法由助记词恢复，请妥善备份密钥文件")
+	}
+	if reveal {
+		pw := password
+		if !supplied {
+			pw, err = promptSecret("请再次输入账户口令以展示助记词: ")
+			if err != nil {
+				return err
+			}
+		}
+		m, err := w.RevealMnemonic(pw)
+		if err == wallet.ErrMnemonicUnavailable {
+			fmt.Println("  钱包助记词: 未保存")
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Println("  钱包助记词:", m)
+	}
+	return nil
+}
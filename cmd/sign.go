@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var signature string
+
+func signCMD() *cobra.Command {
+	c := &cobra.Command{
+		Use:   SubCMDSign + " [file]",
+		Short: "使用钱包私钥对文件或标准输入内容签名",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return sign(args)
+		},
+	}
+	c.Flags().StringVarP(&name, "name", "n", "", "账户名称")
+	return c
+}
+
+func verifyCMD() *cobra.Command {
+	c := &cobra.Command{
+		Use:   SubCMDVerify + " [file]",
+		Short: "使用钱包公钥验证文件或标准输入内容的签名",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return verify(args)
+		},
+	}
+	c.Flags().StringVarP(&name, "name", "n", "", "账户名称")
+	c.Flags().StringVarP(&signature, "signature", "s", "", "签名（hex 编码）")
+	return c
+}
+
+func sign(args []string) error {
+	w, err := loadWallet()
+	if err != nil {
+		return err
+	}
+	data, err := readInput(args)
+	if err != nil {
+		return err
+	}
+	sig, err := w.Sign(data)
+	if err != nil {
+		return err
+	}
+	fmt.Println(hex.EncodeToString(sig))
+	return nil
+}
+
+func verify(args []string) error {
+	if len(signature) == 0 {
+		return errors.New("缺少签名，请通过 -s 指定")
+	}
+	sig, err := hex.DecodeString(strings.TrimSpace(signature))
+	if err != nil {
+		return fmt.Errorf("签名格式错误: %s", err)
+	}
+	w, err := loadWallet()
+	if err != nil {
+		return err
+	}
+	data, err := readInput(args)
+	if err != nil {
+		return err
+	}
+	ok, err := w.Verify(sig, data)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("签名验证失败")
+	}
+	fmt.Println("签名验证成功")
+	return nil
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	shareCount int
+	threshold  int
+)
+
+func splitCMD() *cobra.Command {
+	c := &cobra.Command{
+		Use:   SubCMDSplit,
+		Short: "将钱包助记词拆分为多份份额，凭其中任意 threshold 份可恢复钱包",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return split()
+		},
+	}
+	c.Flags().StringVarP(&name, "name", "n", "", "账户名称")
+	c.Flags().IntVar(&shareCount, "shares", 5, "份额总数")
+	c.Flags().IntVar(&threshold, "threshold", 3, "恢复所需份额数")
+	return c
+}
+
+func split() error {
+	w, err := loadWallet()
+	if err != nil {
+		return err
+	}
+	shares, err := w.SplitMnemonic(password, shareCount, threshold)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("助记词已拆分为 %d 份份额，恢复时需要其中任意 %d 份：\n", shareCount, threshold)
+	for i, s := range shares {
+		fmt.Printf("  份额 %d: %s\n", i+1, s)
+	}
+	if w.HasPassphrase() {
+		fmt.Println("  注意: 份额中不包含助记词口令，恢复时需另行提供")
+	}
+	return nil
+}
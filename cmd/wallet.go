@@ -1,10 +1,15 @@
 package cmd
 
 import (
+	"bufio"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	"bewallet/pkg/keystore"
@@ -13,7 +18,39 @@ import (
 
 // subcommand name
 const (
-	SubCMDCreate = "create"
+	SubCMDCreate        = "create"
+	SubCMDRecover       = "recover"
+	SubCMDList          = "list"
+	SubCMDShow          = "show"
+	SubCMDSign          = "sign"
+	SubCMDVerify        = "verify"
+	SubCMDExportPubkey  = "export-pubkey"
+	SubCMDDerive        = "derive"
+	SubCMDMigrate       = "migrate"
+	SubCMDExportKey     = "export-key"
+	SubCMDImportKey     = "import-key"
+	SubCMDPasswd        = "passwd"
+	SubCMDDoctor        = "doctor"
+	SubCMDDelete        = "delete"
+	SubCMDRename        = "rename"
+	SubCMDArchive       = "archive"
+	SubCMDBackup        = "backup"
+	SubCMDRestore       = "restore"
+	SubCMDSplit         = "split"
+	SubCMDImportMSP     = "import-msp"
+	SubCMDExportMSP     = "export-msp"
+	SubCMDEnroll        = "enroll"
+	SubCMDReenroll      = "reenroll"
+	SubCMDRegister      = "register"
+	SubCMDCerts         = "certs"
+	SubCMDCAInit        = "ca-init"
+	SubCMDCAIssue       = "ca-issue"
+	SubCMDCAEnroll      = "ca-enroll"
+	SubCMDCSR           = "csr"
+	SubCMDLabel         = "label"
+	SubCMDNetworks      = "networks"
+	SubCMDImportProfile = "import-profile"
+	SubCMDExportProfile = "export-profile"
 )
 
 const (
@@ -23,91 +60,153 @@ const (
 var (
 	// WalletCMD .
 	WalletCMD = cobra.Command{
-		Use:   "wallet",
-		Short: "fabric wallet",
-		Run: func(cmd *cobra.Command, args []string) {
-			if len(args) == 0 {
-				printHelp()
-				return
-			}
-			fn := args[0]
-			switch fn {
-			case SubCMDCreate:
-				create()
-			}
-		},
+		Use:           "wallet",
+		Short:         "wallet 是一个基于 fabric 体系的钱包客户端",
+		SilenceUsage:  true,
+		SilenceErrors: true,
 	}
 
 	name     string
 	password string
 	mnemonic string
 	basedir  string
+	path     string
+	strict   bool
+
+	// noPassword 新建密钥缓存目录时不设置账户口令
+	noPassword bool
+
+	language   string
+	passphrase string
+
+	// 口令类参数的来源，见 readSecret
+	passwordFrom   string
+	mnemonicFrom   string
+	passphraseFrom string
 )
 
 func init() {
-	WalletCMD.Flags().StringVarP(&name, "name", "n", "", "账户名称")
-	WalletCMD.Flags().StringVarP(&password, "password", "p", "", "账户口令")
-	WalletCMD.Flags().StringVarP(&mnemonic, "mnemonic", "m", "", "助记词（空格连接）")
-	WalletCMD.Flags().StringVarP(&basedir, "basedir", "d", "", "账户缓存目录")
+	WalletCMD.PersistentFlags().StringVar(&passwordFrom, "password-from", "", "账户口令来源"+sourceUsage)
+	WalletCMD.PersistentFlags().BoolVar(&noPassword, "no-password", false, "新建密钥缓存目录时不设置账户口令，密钥文件不加密（仅用于测试）")
+	WalletCMD.PersistentFlags().StringVarP(&basedir, "basedir", "d", "", "账户缓存目录")
+	WalletCMD.PersistentFlags().BoolVar(&strict, "strict", false, "拒绝加载权限不安全的密钥文件")
+
+	WalletCMD.AddCommand(
+		createCMD(),
+		recoverCMD(),
+		listCMD(),
+		showCMD(),
+		signCMD(),
+		verifyCMD(),
+		exportPubkeyCMD(),
+		deriveCMD(),
+		migrateCMD(),
+		exportKeyCMD(),
+		importKeyCMD(),
+		passwdCMD(),
+		doctorCMD(),
+		deleteCMD(),
+		renameCMD(),
+		archiveCMD(),
+		backupCMD(),
+		restoreCMD(),
+		splitCMD(),
+		importMSPCMD(),
+		exportMSPCMD(),
+		enrollCMD(),
+		reenrollCMD(),
+		registerCMD(),
+		certsCMD(),
+		caInitCMD(),
+		caIssueCMD(),
+		caEnrollCMD(),
+		csrCMD(),
+		labelCMD(),
+		networksCMD(),
+		importProfileCMD(),
+		exportProfileCMD(),
+	)
+}
+
+// Execute 执行钱包命令，出错时输出错误信息并以非零状态码退出
+func Execute() {
+	if err := WalletCMD.Execute(); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
 }
 
-func create() error {
+func getBaseDir() (string, error) {
 	if len(basedir) == 0 {
 		userdir, err := os.UserHomeDir()
 		if err != nil {
-			return err
+			return "", err
 		}
 		basedir = filepath.Join(userdir, defaultSubBaseDir)
-		fmt.Println("密钥缓存目录:", basedir)
+		fmt.Fprintln(os.Stderr, "密钥缓存目录:", basedir)
+	}
+	return basedir, nil
+}
+
+func getKeyStore() (*keystore.FileKeyStore, error) {
+	basedir, err := getBaseDir()
+	if err != nil {
+		return nil, err
+	}
+	if _, serr := os.Stat(filepath.Join(basedir, keystore.TagFile)); os.IsNotExist(serr) {
+		if !noPassword {
+			err = readNewSecret(&password, passwordFrom, "请设置账户口令: ")
+		}
+	} else {
+		err = readSecret(&password, passwordFrom, "请输入账户口令: ")
 	}
-	ks, err := keystore.NewFilKeyStore(basedir, password)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	w, err := wallet.CreateWallet(ks, name)
+	ks, err := keystore.NewFilKeyStore(basedir, password, keystore.WithStrict(strict))
 	if err != nil {
-		return err
+		return nil, errors.WithMessagef(err, "打开密钥缓存目录 %s 失败", basedir)
 	}
-	fmt.Println("钱包创建成功！")
-	fmt.Println("  钱包助记词:", w.ShowMnemonic())
-	fmt.Println("  钱包地址:", w.Address())
-	return nil
+	return ks, nil
 }
 
-func printHelp() {
-	fmt.Println("wallet 是一个基于 fabric 体系的钱包客户端")
-	fmt.Println("Usage:")
-	fmt.Println("    wallet <command> [arguments]")
-	fmt.Println()
-	fmt.Println("The commands are:")
-	fmt.Println("  create - 创建钱包")
-	fmt.Println()
-	fmt.Println("The arguments are:")
-	fmt.Println("    -n  name       账户名称")
-	fmt.Println("    -p  password   账户口令")
-	fmt.Println("    -d  basedir    缓存目录")
-	fmt.Println("    -m  mnemonic   助记词")
+func loadWallet() (*wallet.Wallet, error) {
+	if len(name) == 0 {
+		return nil, errors.New("缺少账户名称，请通过 -n 指定")
+	}
+	ks, err := getKeyStore()
+	if err != nil {
+		return nil, err
+	}
+	w, err := wallet.LoadWallet(ks, name)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "加载账户 %s 失败", name)
+	}
+	return w, nil
 }
 
-// ks := wallet.NewFilKeyStore("./wallet", "wallet123")
-
-// 	// w, err := wallet.CreateWallet(ks, "tinywell323")
-// 	w, err := wallet.LoadWallet(ks, "tinywell")
-// 	// w, err := wallet.RecoverWallet(ks, "retiynwell", "造 促 桃 作 最 包 送 郑 综 于 张 讯")
-// 	if err != nil {
-// 		panic(err)
-// 	}
-
-// 	fmt.Println(w.ShowMnemonic())
-// 	fmt.Println(w.Address())
+// readInput 读取文件内容，未指定文件或文件为 "-" 时读取标准输入
+func readInput(args []string) ([]byte, error) {
+	var r io.Reader = os.Stdin
+	if len(args) > 0 && args[0] != "-" {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	return ioutil.ReadAll(r)
+}
 
-// 	sig, err := w.Sign([]byte("hello world"))
-// 	if err != nil {
-// 		panic(err)
-// 	}
+var stdin = bufio.NewReader(os.Stdin)
 
-// 	res, err := w.Verify(sig, []byte("hello world"))
-// 	if err != nil {
-// 		panic(err)
-// 	}
-// 	fmt.Println("sig is", res)
+// prompt 在标准错误输出提示并从标准输入读取一行
+func prompt(msg string) (string, error) {
+	fmt.Fprint(os.Stderr, msg)
+	line, err := stdin.ReadString('\n')
+	if err != nil && (err != io.EOF || len(line) == 0) {
+		return "", errors.Wrap(err, "读取输入失败")
+	}
+	return strings.TrimSpace(line), nil
+}
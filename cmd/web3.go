@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/spf13/cobra"
+
+	"bewallet/pkg/wallet"
+)
+
+var (
+	keyPassword     string
+	keyPasswordFrom string
+)
+
+func exportKeyCMD() *cobra.Command {
+	c := &cobra.Command{
+		Use:   SubCMDExportKey,
+		Short: "导出钱包私钥为 keystore v3 JSON 文件",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return exportKey()
+		},
+	}
+	c.Flags().StringVarP(&name, "name", "n", "", "账户名称")
+	c.Flags().StringVarP(&output, "output", "o", "", "输出文件，默认输出到标准输出")
+	c.Flags().StringVar(&keyPasswordFrom, "key-password-from", "", "keystore v3 文件口令来源"+sourceUsage)
+	return c
+}
+
+func importKeyCMD() *cobra.Command {
+	c := &cobra.Command{
+		Use:   SubCMDImportKey + " <file>",
+		Short: "从 keystore v3 JSON 文件导入钱包私钥",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return importKey(args[0])
+		},
+	}
+	c.Flags().StringVarP(&name, "name", "n", "", "账户名称，默认为账户地址")
+	c.Flags().StringVar(&keyPasswordFrom, "key-password-from", "", "keystore v3 文件口令来源"+sourceUsage)
+	return c
+}
+
+func exportKey() error {
+	err := readNewSecret(&keyPassword, keyPasswordFrom, "请设置 keystore v3 文件口令: ")
+	if err != nil {
+		return err
+	}
+	w, err := loadWallet()
+	if err != nil {
+		return err
+	}
+	data, err := w.ExportWeb3Key(password, keyPassword)
+	if err != nil {
+		return err
+	}
+	if len(output) == 0 {
+		fmt.Println(string(data))
+		return nil
+	}
+	return ioutil.WriteFile(output, data, 0600)
+}
+
+func importKey(file string) error {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	err = readSecret(&keyPassword, keyPasswordFrom, "请输入 keystore v3 文件口令: ")
+	if err != nil {
+		return err
+	}
+	ks, err := getKeyStore()
+	if err != nil {
+		return err
+	}
+	w, err := wallet.ImportWeb3Key(ks, name, data, keyPassword)
+	if err != nil {
+		return err
+	}
+	fmt.Println("钱包导入成功！")
+	fmt.Println("  钱包地址:", w.Address())
+	return nil
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bewallet/cmd"
 	"bewallet/pkg/fab/sdk"
 	"bewallet/pkg/wallet"
 )
@@ -8,9 +9,5 @@ import (
 var _ sdk.Signer = &wallet.FabWallet{}
 
 func main() {
-	// if err := cmd.WalletCMD.Execute(); err != nil {
-	// 	fmt.Println(err)
-	// 	os.Exit(0)
-	// }
-
+	cmd.Execute()
 }
@@ -0,0 +1,304 @@
+package fabca
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Fabric CA REST 接口
+const (
+	enrollPath   = "/api/v1/enroll"
+	reenrollPath = "/api/v1/reenroll"
+	registerPath = "/api/v1/register"
+
+	defaultTimeout = 30 * time.Second
+)
+
+// Signer 签名者，对数据做 SHA-256 摘要后签名，返回 DER 编码的 low-S ECDSA 签名
+type Signer interface {
+	Sign(data []byte) ([]byte, error)
+}
+
+// Identity 已登记的身份，用于生成认证令牌
+type Identity struct {
+	Cert   []byte // PEM 证书
+	Signer Signer // 证书对应私钥的签名者
+}
+
+// Client Fabric CA REST 客户端
+type Client struct {
+	url    string
+	caName string
+	hc     *http.Client
+}
+
+// Option Client 参数
+type Option func(c *Client)
+
+// WithCAName 指定 CA 名称，Fabric CA 服务端运行多个 CA 时使用
+func WithCAName(name string) Option {
+	return func(c *Client) {
+		c.caName = name
+	}
+}
+
+// WithHTTPClient 指定 HTTP 客户端，默认使用 30 秒超时的客户端
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.hc = hc
+	}
+}
+
+// WithTLSCACerts 指定校验 Fabric CA 服务端 TLS 证书的根证书（PEM）
+func WithTLSCACerts(certs ...[]byte) Option {
+	return func(c *Client) {
+		pool := x509.NewCertPool()
+		for _, cert := range certs {
+			pool.AppendCertsFromPEM(cert)
+		}
+		c.hc = &http.Client{
+			Timeout: defaultTimeout,
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{RootCAs: pool},
+			},
+		}
+	}
+}
+
+// NewClient 生成 Fabric CA 客户端，rawURL 为 CA 服务地址，如 https://ca.org1.example.com:7054
+func NewClient(rawURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "CA 地址 %s 格式错误", rawURL)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || len(u.Host) == 0 {
+		return nil, errors.Errorf("CA 地址 %s 格式错误，应为 http(s)://host:port", rawURL)
+	}
+	c := &Client{
+		url: strings.TrimRight(rawURL, "/"),
+		hc:  &http.Client{Timeout: defaultTimeout},
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c, nil
+}
+
+// AttributeRequest 登记时申请写入证书的属性
+type AttributeRequest struct {
+	Name     string `json:"name"`
+	Optional bool   `json:"optional,omitempty"`
+}
+
+// Attribute 注册身份时设置的属性
+type Attribute struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	ECert bool   `json:"ecert,omitempty"`
+}
+
+// EnrollResponse 登记结果
+type EnrollResponse struct {
+	Cert    []byte // PEM 证书
+	CAChain []byte // PEM CA 证书链
+	CAName  string
+	Version string
+}
+
+// RegistrationRequest 注册身份请求
+type RegistrationRequest struct {
+	Name           string      `json:"id"`
+	Type           string      `json:"type,omitempty"`
+	Secret         string      `json:"secret,omitempty"`
+	MaxEnrollments int         `json:"max_enrollments,omitempty"`
+	Affiliation    string      `json:"affiliation"`
+	Attributes     []Attribute `json:"attrs,omitempty"`
+	CAName         string      `json:"caname,omitempty"`
+}
+
+type enrollRequest struct {
+	CSR      string             `json:"certificate_request"`
+	Profile  string             `json:"profile,omitempty"`
+	Label    string             `json:"label,omitempty"`
+	CAName   string             `json:"caname,omitempty"`
+	AttrReqs []AttributeRequest `json:"attr_reqs,omitempty"`
+}
+
+type enrollResult struct {
+	Cert       string
+	ServerInfo struct {
+		CAName  string
+		CAChain string
+		Version string
+	}
+}
+
+type response struct {
+	Success bool            `json:"success"`
+	Result  json.RawMessage `json:"result"`
+	Errors  []struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// Enroll 使用登记 ID 和口令为 csr（DER 或 PEM）申请证书
+func (c *Client) Enroll(enrollID, secret string, csr []byte, attrs ...AttributeRequest) (*EnrollResponse, error) {
+	body, err := json.Marshal(&enrollRequest{
+		CSR:      string(csrPEM(csr)),
+		CAName:   c.caName,
+		AttrReqs: attrs,
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := c.newRequest(enrollPath, body)
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(enrollID, secret)
+	return c.enroll(req)
+}
+
+// Reenroll 使用已登记的身份为 csr（DER 或 PEM）重新申请证书，用于证书续期
+func (c *Client) Reenroll(id *Identity, csr []byte, attrs ...AttributeRequest) (*EnrollResponse, error) {
+	body, err := json.Marshal(&enrollRequest{
+		CSR:      string(csrPEM(csr)),
+		CAName:   c.caName,
+		AttrReqs: attrs,
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := c.newTokenRequest(id, reenrollPath, body)
+	if err != nil {
+		return nil, err
+	}
+	return c.enroll(req)
+}
+
+// Register 使用管理员身份注册新身份，返回登记口令
+func (c *Client) Register(admin *Identity, r *RegistrationRequest) (string, error) {
+	if len(r.Name) == 0 {
+		return "", errors.New("缺少注册身份 ID")
+	}
+	rr := *r
+	if len(rr.CAName) == 0 {
+		rr.CAName = c.caName
+	}
+	body, err := json.Marshal(&rr)
+	if err != nil {
+		return "", err
+	}
+	req, err := c.newTokenRequest(admin, registerPath, body)
+	if err != nil {
+		return "", err
+	}
+	result := &struct {
+		Secret string `json:"secret"`
+	}{}
+	err = c.do(req, result)
+	if err != nil {
+		return "", err
+	}
+	return result.Secret, nil
+}
+
+func (c *Client) enroll(req *http.Request) (*EnrollResponse, error) {
+	result := &enrollResult{}
+	err := c.do(req, result)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := base64.StdEncoding.DecodeString(result.Cert)
+	if err != nil {
+		return nil, errors.Wrap(err, "解析 CA 返回的证书失败")
+	}
+	chain, err := base64.StdEncoding.DecodeString(result.ServerInfo.CAChain)
+	if err != nil {
+		return nil, errors.Wrap(err, "解析 CA 返回的证书链失败")
+	}
+	return &EnrollResponse{
+		Cert:    cert,
+		CAChain: chain,
+		CAName:  result.ServerInfo.CAName,
+		Version: result.ServerInfo.Version,
+	}, nil
+}
+
+func (c *Client) newRequest(path string, body []byte) (*http.Request, error) {
+	req, err := http.NewRequest(http.MethodPost, c.url+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
+
+// newTokenRequest 生成携带 Fabric CA 认证令牌的请求，令牌为
+// base64(证书) + "." + base64(签名)，签名内容为 method.base64(uri).base64(body).base64(证书)
+func (c *Client) newTokenRequest(id *Identity, path string, body []byte) (*http.Request, error) {
+	if id == nil || len(id.Cert) == 0 || id.Signer == nil {
+		return nil, errors.New("缺少用于认证的证书或签名者")
+	}
+	req, err := c.newRequest(path, body)
+	if err != nil {
+		return nil, err
+	}
+	b64cert := base64.StdEncoding.EncodeToString(id.Cert)
+	payload := req.Method + "." +
+		base64.StdEncoding.EncodeToString([]byte(req.URL.RequestURI())) + "." +
+		base64.StdEncoding.EncodeToString(body) + "." +
+		b64cert
+	sig, err := id.Signer.Sign([]byte(payload))
+	if err != nil {
+		return nil, errors.WithMessage(err, "生成认证令牌失败")
+	}
+	req.Header.Set("Authorization", b64cert+"."+base64.StdEncoding.EncodeToString(sig))
+	return req, nil
+}
+
+func (c *Client) do(req *http.Request, result interface{}) error {
+	resp, err := c.hc.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "请求 %s 失败", req.URL)
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrapf(err, "读取 %s 响应失败", req.URL)
+	}
+	r := &response{}
+	err = json.Unmarshal(data, r)
+	if err != nil {
+		return errors.Errorf("%s 响应格式错误，HTTP 状态码 %d", req.URL, resp.StatusCode)
+	}
+	if !r.Success || resp.StatusCode != http.StatusOK {
+		msgs := []string{}
+		for _, e := range r.Errors {
+			msgs = append(msgs, fmt.Sprintf("%d %s", e.Code, e.Message))
+		}
+		return errors.Errorf("CA 返回错误（HTTP %d）: %s", resp.StatusCode, strings.Join(msgs, "; "))
+	}
+	return json.Unmarshal(r.Result, result)
+}
+
+// csrPEM 将 DER 格式的证书请求转换为 PEM，已是 PEM 时原样返回
+func csrPEM(csr []byte) []byte {
+	if block, _ := pem.Decode(csr); block != nil {
+		return csr
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csr})
+}
@@ -0,0 +1,407 @@
+package fabca
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type testSigner struct {
+	key *ecdsa.PrivateKey
+}
+
+func (s *testSigner) Sign(data []byte) ([]byte, error) {
+	h := sha256.Sum256(data)
+	return ecdsa.SignASN1(rand.Reader, s.key, h[:])
+}
+
+// testCA 模拟 Fabric CA 服务端：登记 ID 为 admin、口令为 adminpw，签发证书时使用 CSR 中的公钥
+type testCA struct {
+	t       *testing.T
+	key     *ecdsa.PrivateKey
+	cert    *x509.Certificate
+	certPEM []byte
+	serial  int64
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCA{
+		t:       t,
+		key:     key,
+		cert:    cert,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		serial:  1,
+	}
+}
+
+func (ca *testCA) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		ca.fail(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	switch r.URL.Path {
+	case enrollPath:
+		id, secret, ok := r.BasicAuth()
+		if !ok || id != "admin" || secret != "adminpw" {
+			ca.fail(w, http.StatusUnauthorized, "Authentication failure")
+			return
+		}
+		ca.issue(w, body)
+	case reenrollPath:
+		if _, err := ca.authenticate(r, body); err != nil {
+			ca.fail(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+		ca.issue(w, body)
+	case registerPath:
+		if _, err := ca.authenticate(r, body); err != nil {
+			ca.fail(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+		req := &RegistrationRequest{}
+		if err := json.Unmarshal(body, req); err != nil {
+			ca.fail(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if req.Name == "exists" {
+			ca.fail(w, http.StatusConflict, "Identity 'exists' is already registered")
+			return
+		}
+		ca.succeed(w, map[string]string{"secret": req.Name + "pw"})
+	default:
+		ca.fail(w, http.StatusNotFound, "not found")
+	}
+}
+
+// authenticate 按 Fabric CA 的规则校验 Authorization 令牌 b64cert.b64sig
+func (ca *testCA) authenticate(r *http.Request, body []byte) (*x509.Certificate, error) {
+	token := r.Header.Get("Authorization")
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return nil, errors.Errorf("令牌格式错误: %q", token)
+	}
+	certPEM, err := base64.StdEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, err
+	}
+	sig, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return nil, errors.New("令牌中的证书不是 PEM 格式")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	if err := cert.CheckSignatureFrom(ca.cert); err != nil {
+		return nil, err
+	}
+	payload := r.Method + "." +
+		base64.StdEncoding.EncodeToString([]byte(r.URL.RequestURI())) + "." +
+		base64.StdEncoding.EncodeToString(body) + "." +
+		parts[0]
+	h := sha256.Sum256([]byte(payload))
+	if !ecdsa.VerifyASN1(cert.PublicKey.(*ecdsa.PublicKey), h[:], sig) {
+		return nil, errors.New("令牌签名校验失败")
+	}
+	return cert, nil
+}
+
+func (ca *testCA) issue(w http.ResponseWriter, body []byte) {
+	req := &enrollRequest{}
+	if err := json.Unmarshal(body, req); err != nil {
+		ca.fail(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	block, _ := pem.Decode([]byte(req.CSR))
+	if block == nil {
+		ca.fail(w, http.StatusBadRequest, "invalid csr")
+		return
+	}
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		ca.fail(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	ca.serial++
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(ca.serial),
+		Subject:      csr.Subject,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, csr.PublicKey, ca.key)
+	if err != nil {
+		ca.fail(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	result := &enrollResult{Cert: base64.StdEncoding.EncodeToString(cert)}
+	result.ServerInfo.CAName = req.CAName
+	result.ServerInfo.CAChain = base64.StdEncoding.EncodeToString(ca.certPEM)
+	result.ServerInfo.Version = "1.5.0"
+	ca.succeed(w, result)
+}
+
+func (ca *testCA) succeed(w http.ResponseWriter, result interface{}) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		ca.t.Error(err)
+	}
+	json.NewEncoder(w).Encode(&response{Success: true, Result: data})
+}
+
+func (ca *testCA) fail(w http.ResponseWriter, status int, msg string) {
+	w.WriteHeader(status)
+	r := map[string]interface{}{
+		"success": false,
+		"result":  nil,
+		"errors":  []map[string]interface{}{{"code": 20, "message": msg}},
+	}
+	json.NewEncoder(w).Encode(r)
+}
+
+func newCSR(t *testing.T, key *ecdsa.PrivateKey, cn string) []byte {
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject: pkix.Name{CommonName: cn},
+	}, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return csr
+}
+
+func parseCert(t *testing.T, data []byte) *x509.Certificate {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		t.Fatal("证书不是 PEM 格式")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+// enrollAdmin 登记 admin 身份，返回可用于令牌认证的 Identity
+func enrollAdmin(t *testing.T, c *Client) *Identity {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := c.Enroll("admin", "adminpw", newCSR(t, key, "admin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Identity{Cert: resp.Cert, Signer: &testSigner{key: key}}
+}
+
+func TestEnroll(t *testing.T) {
+	ca := newTestCA(t)
+	srv := httptest.NewServer(ca)
+	defer srv.Close()
+	c, err := NewClient(srv.URL, WithCAName("ca-org1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := c.Enroll("admin", "adminpw", newCSR(t, key, "admin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert := parseCert(t, resp.Cert)
+	if err := cert.CheckSignatureFrom(ca.cert); err != nil {
+		t.Fatal(err)
+	}
+	if !key.PublicKey.Equal(cert.PublicKey) {
+		t.Fatal("证书公钥与 CSR 公钥不一致")
+	}
+	if string(resp.CAChain) != string(ca.certPEM) || resp.CAName != "ca-org1" || resp.Version != "1.5.0" {
+		t.Fatalf("登记结果不正确: %+v", resp)
+	}
+
+	if _, err := c.Enroll("admin", "wrong", newCSR(t, key, "admin")); err == nil {
+		t.Fatal("登记口令错误时应返回错误")
+	}
+}
+
+func TestReenroll(t *testing.T) {
+	ca := newTestCA(t)
+	srv := httptest.NewServer(ca)
+	defer srv.Close()
+	c, err := NewClient(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := enrollAdmin(t, c)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := c.Reenroll(id, newCSR(t, key, "admin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert := parseCert(t, resp.Cert)
+	if !key.PublicKey.Equal(cert.PublicKey) {
+		t.Fatal("重新登记的证书公钥与 CSR 公钥不一致")
+	}
+
+	// 令牌签名与证书不匹配时 CA 拒绝请求
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged := &Identity{Cert: id.Cert, Signer: &testSigner{key: other}}
+	if _, err := c.Reenroll(forged, newCSR(t, key, "admin")); err == nil {
+		t.Fatal("令牌签名错误时应返回错误")
+	}
+	if _, err := c.Reenroll(&Identity{}, newCSR(t, key, "admin")); err == nil {
+		t.Fatal("缺少证书时应返回错误")
+	}
+}
+
+func TestTokenFormat(t *testing.T) {
+	c, err := NewClient("http://localhost:7054")
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert := []byte("-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n")
+	body := []byte(`{"id":"user1"}`)
+	req, err := c.newTokenRequest(&Identity{Cert: cert, Signer: &testSigner{key: key}}, registerPath, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(req.Header.Get("Authorization"), ".")
+	if len(parts) != 2 {
+		t.Fatalf("令牌应为 b64cert.b64sig，实际为 %q", req.Header.Get("Authorization"))
+	}
+	if parts[0] != base64.StdEncoding.EncodeToString(cert) {
+		t.Fatal("令牌中的证书不正确")
+	}
+	sig, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := "POST." + base64.StdEncoding.EncodeToString([]byte(registerPath)) + "." +
+		base64.StdEncoding.EncodeToString(body) + "." + parts[0]
+	h := sha256.Sum256([]byte(payload))
+	if !ecdsa.VerifyASN1(&key.PublicKey, h[:], sig) {
+		t.Fatal("令牌签名校验失败")
+	}
+}
+
+func TestRegister(t *testing.T) {
+	ca := newTestCA(t)
+	srv := httptest.NewServer(ca)
+	defer srv.Close()
+	c, err := NewClient(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	admin := enrollAdmin(t, c)
+
+	secret, err := c.Register(admin, &RegistrationRequest{Name: "user1", Affiliation: "org1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret != "user1pw" {
+		t.Fatalf("登记口令应为 user1pw，实际为 %s", secret)
+	}
+	if _, err := c.Register(admin, &RegistrationRequest{}); err == nil {
+		t.Fatal("缺少身份 ID 时应返回错误")
+	}
+}
+
+func TestErrorResponse(t *testing.T) {
+	ca := newTestCA(t)
+	srv := httptest.NewServer(ca)
+	defer srv.Close()
+	c, err := NewClient(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	admin := enrollAdmin(t, c)
+
+	_, err = c.Register(admin, &RegistrationRequest{Name: "exists"})
+	if err == nil {
+		t.Fatal("CA 返回 success:false 时应返回错误")
+	}
+	if !strings.Contains(err.Error(), "409") || !strings.Contains(err.Error(), "already registered") {
+		t.Fatalf("错误信息应包含 HTTP 状态码及 CA 返回的错误，实际为 %v", err)
+	}
+
+	// HTTP 200 但 success 为 false 时同样返回错误
+	bad := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success":false,"result":null,"errors":[{"code":71,"message":"Authorization failure"}]}`))
+	}))
+	defer bad.Close()
+	c, err = NewClient(bad.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.Enroll("admin", "adminpw", []byte("csr"))
+	if err == nil || !strings.Contains(err.Error(), "71 Authorization failure") {
+		t.Fatalf("应返回 CA 的错误信息，实际为 %v", err)
+	}
+
+	// 响应不是 JSON
+	html := httptest.NewServer(http.NotFoundHandler())
+	defer html.Close()
+	c, err = NewClient(html.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = c.Enroll("admin", "adminpw", []byte("csr")); err == nil {
+		t.Fatal("响应格式错误时应返回错误")
+	}
+}
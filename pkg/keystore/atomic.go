@@ -0,0 +1,77 @@
+package keystore
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// keystore 文件
+const (
+	LockFile     = ".lock"
+	BackupSuffix = ".bak"
+)
+
+// lock 对 baseDir 加进程间互斥的建议锁，返回解锁函数
+func (fk *FileKeyStore) lock() (func(), error) {
+	err := os.MkdirAll(fk.baseDir, DirPerm)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(filepath.Join(fk.baseDir, LockFile), os.O_CREATE|os.O_RDWR, FilePerm)
+	if err != nil {
+		return nil, err
+	}
+	err = lockFile(f)
+	if err != nil {
+		f.Close()
+		return nil, errors.Wrap(err, "锁定密钥缓存目录失败")
+	}
+	return func() {
+		unlockFile(f)
+		f.Close()
+	}, nil
+}
+
+// writeFileAtomic 先写入同目录下的临时文件（权限 0600）并 fsync，再改名覆盖目标文件，
+// 崩溃时目标文件要么是旧内容要么是新内容，不会出现写了一半的文件
+func writeFileAtomic(filename string, data []byte) (err error) {
+	dir := filepath.Dir(filename)
+	f, err := ioutil.TempFile(dir, "."+filepath.Base(filename)+".tmp-")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+	if _, err = f.Write(data); err != nil {
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(f.Name(), filename); err != nil {
+		return err
+	}
+	return syncDir(dir)
+}
+
+// backupFile 将文件当前内容保存为 .bak 备份，文件不存在时忽略
+func backupFile(filename string) error {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	return writeFileAtomic(filename+BackupSuffix, data)
+}
@@ -0,0 +1,146 @@
+package keystore
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadFallsBackToBackup(t *testing.T) {
+	dir := t.TempDir()
+	fk, err := NewFilKeyStore(dir, "Passw0rd!x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, content := range []string{"v1", "v2"} {
+		if err := fk.Store(&SecretStoreOpt{Name: "alice", Content: []byte(content)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	file := filepath.Join(dir, "alice", "alice.sec")
+	if err := ioutil.WriteFile(file, []byte("corrupted"), FilePerm); err != nil {
+		t.Fatal(err)
+	}
+	data, err := fk.Load(&SecretLoadOpt{Name: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "v1" {
+		t.Fatalf(".sec 损坏时应读取 .bak 备份，实际为 %q", data)
+	}
+
+	if err := os.Remove(file); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fk.Load(&SecretLoadOpt{Name: "alice"}); !os.IsNotExist(err) {
+		t.Fatalf(".sec 不存在时不应读取备份，实际为 %v", err)
+	}
+}
+
+func TestLeftoverTempFilesIgnored(t *testing.T) {
+	dir := t.TempDir()
+	fk, err := NewFilKeyStore(dir, "Passw0rd!x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fk.Store(&SecretStoreOpt{Name: "alice", Content: []byte("alice")}); err != nil {
+		t.Fatal(err)
+	}
+	// 模拟写入临时文件后崩溃
+	for _, file := range []string{
+		filepath.Join(dir, "."+TagFile+".tmp-1"),
+		filepath.Join(dir, "alice", ".alice.sec.tmp-1"),
+	} {
+		if err := ioutil.WriteFile(file, []byte("partial"), FilePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fk, err = NewFilKeyStore(dir, "Passw0rd!x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	list, err := fk.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0] != "alice" {
+		t.Fatalf("账户列表为 %v", list)
+	}
+	data, err := fk.Load(&SecretLoadOpt{Name: "alice"})
+	if err != nil || string(data) != "alice" {
+		t.Fatalf("读取密钥失败: %q %v", data, err)
+	}
+	if err := fk.ChangePassword("Passw0rd!x", "N3w-Passw0rd"); err != nil {
+		t.Fatal("修改口令应忽略残留的临时文件:", err)
+	}
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file")
+	if err := writeFileAtomic(file, []byte("data")); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0600 {
+		t.Fatalf("文件权限为 %#o", info.Mode().Perm())
+	}
+	// 目标为目录时改名失败，临时文件应被删除
+	if err := os.Mkdir(filepath.Join(dir, "sub"), DirPerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFileAtomic(filepath.Join(dir, "sub"), []byte("data")); err == nil {
+		t.Fatal("目标为目录时应写入失败")
+	}
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if strings.Contains(e.Name(), ".tmp-") {
+			t.Fatalf("残留临时文件 %s", e.Name())
+		}
+	}
+}
+
+func TestLockSerializesWrites(t *testing.T) {
+	dir := t.TempDir()
+	a, err := NewFilKeyStore(dir, "Passw0rd!x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewFilKeyStore(dir, "Passw0rd!x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	unlock, err := a.lock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- b.Store(&SecretStoreOpt{Name: "bob", Content: []byte("bob")})
+	}()
+	select {
+	case err := <-done:
+		unlock()
+		t.Fatalf("持有锁时另一个密钥存储不应写入: %v", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+	unlock()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	data, err := a.Load(&SecretLoadOpt{Name: "bob"})
+	if err != nil || string(data) != "bob" {
+		t.Fatalf("读取密钥失败: %q %v", data, err)
+	}
+}
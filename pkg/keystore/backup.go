@@ -0,0 +1,215 @@
+package keystore
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"bewallet/pkg/utils"
+)
+
+// BackupVersion 备份文件格式版本
+const BackupVersion = 1
+
+// ConflictMode 恢复备份时账户重名的处理方式
+type ConflictMode string
+
+// 重名处理方式
+const (
+	ConflictSkip      ConflictMode = "skip"      // 跳过备份中的账户
+	ConflictOverwrite ConflictMode = "overwrite" // 用备份中的账户覆盖已有账户
+	ConflictRename    ConflictMode = "rename"    // 备份中的账户改名后恢复
+)
+
+type backupArchive struct {
+	Version  int             `json:"version"`
+	Created  time.Time       `json:"created"`
+	Accounts []backupAccount `json:"accounts"`
+}
+
+type backupAccount struct {
+	Name    string `json:"name"`
+	Secret  []byte `json:"secret,omitempty"`
+	Network []byte `json:"network,omitempty"`
+	Meta    []byte `json:"meta,omitempty"`
+}
+
+// RestoreResult 恢复结果
+type RestoreResult struct {
+	Restored []string          // 恢复的账户
+	Skipped  []string          // 因重名跳过的账户
+	Renamed  map[string]string // 因重名改名恢复的账户，备份中的名称 -> 恢复后的名称
+}
+
+// Backup 将 ks 中全部账户的密钥、网络信息及元数据导出为单个备份文件，
+// 备份使用独立的 password 加密（scrypt + AES-256-GCM），可检测篡改。
+// 仅包含 List 返回的账户，已归档的账户不在备份范围内
+func Backup(ks KeyStore, password string) ([]byte, error) {
+	if len(password) == 0 {
+		return nil, errors.New("备份口令不能为空")
+	}
+	list, err := ks.List()
+	if err != nil {
+		return nil, err
+	}
+	bf := &backupArchive{
+		Version: BackupVersion,
+		Created: time.Now().UTC(),
+	}
+	for _, name := range list {
+		sec, err := ks.Load(&SecretLoadOpt{Name: name})
+		if err != nil && !os.IsNotExist(errors.Cause(err)) {
+			return nil, errors.WithMessagef(err, "读取账户 %s 密钥失败", name)
+		}
+		net, err := ks.Load(&NetworkLoadOpt{Name: name})
+		if err != nil {
+			return nil, errors.WithMessagef(err, "读取账户 %s 网络信息失败", name)
+		}
+		meta, err := ks.Load(&MetaLoadOpt{Name: name})
+		if err != nil {
+			return nil, errors.WithMessagef(err, "读取账户 %s 元数据失败", name)
+		}
+		bf.Accounts = append(bf.Accounts, backupAccount{
+			Name:    name,
+			Secret:  sec,
+			Network: net,
+			Meta:    meta,
+		})
+	}
+	data, err := json.Marshal(bf)
+	if err != nil {
+		return nil, err
+	}
+	return utils.Seal(data, []byte(password))
+}
+
+// Restore 将备份文件中的账户恢复到 ks 中，重名账户按 mode 处理
+func Restore(ks KeyStore, data []byte, password string, mode ConflictMode) (*RestoreResult, error) {
+	switch mode {
+	case ConflictSkip, ConflictOverwrite, ConflictRename:
+	default:
+		return nil, errors.Errorf("未知的重名处理方式 %s", mode)
+	}
+	plain, err := utils.Open(data, []byte(password))
+	if err != nil {
+		return nil, errors.WithMessage(err, "解密备份文件失败")
+	}
+	bf := &backupArchive{}
+	err = json.Unmarshal(plain, bf)
+	if err != nil {
+		return nil, errors.Wrap(err, "解析备份文件失败")
+	}
+	if bf.Version != BackupVersion {
+		return nil, errors.Errorf("不支持的备份文件版本 %d", bf.Version)
+	}
+
+	list, err := ks.List()
+	if err != nil {
+		return nil, err
+	}
+	exists := make(map[string]bool, len(list))
+	for _, name := range list {
+		exists[name] = true
+	}
+
+	for _, acc := range bf.Accounts {
+		err = checkName(acc.Name)
+		if err != nil {
+			return nil, errors.WithMessage(err, "备份文件中包含非法账户名称")
+		}
+	}
+
+	result := &RestoreResult{
+		Renamed: make(map[string]string),
+	}
+	for _, acc := range bf.Accounts {
+		name := acc.Name
+		if exists[name] {
+			switch mode {
+			case ConflictSkip:
+				result.Skipped = append(result.Skipped, acc.Name)
+				continue
+			case ConflictOverwrite:
+				err = replaceAccount(ks, exists, acc)
+				if err != nil {
+					return result, err
+				}
+				result.Restored = append(result.Restored, name)
+				continue
+			case ConflictRename:
+				name = freeName(exists, acc.Name)
+				result.Renamed[acc.Name] = name
+			}
+		}
+		err = storeAccount(ks, name, acc)
+		if err != nil {
+			return result, err
+		}
+		exists[name] = true
+		result.Restored = append(result.Restored, name)
+	}
+	return result, nil
+}
+
+// replaceAccount 先将备份中的账户写入临时名称，成功后再删除已有账户并改名，
+// 避免写入失败时已有账户已被删除
+func replaceAccount(ks KeyStore, exists map[string]bool, acc backupAccount) error {
+	id, err := NewUUID()
+	if err != nil {
+		return err
+	}
+	tmp := fmt.Sprintf("%s.restore-%s", acc.Name, id[:8])
+	if exists[tmp] {
+		return errors.Errorf("临时账户 %s 已存在", tmp)
+	}
+	err = storeAccount(ks, tmp, acc)
+	if err != nil {
+		ks.Delete(tmp)
+		return err
+	}
+	err = ks.Delete(acc.Name)
+	if err != nil {
+		ks.Delete(tmp)
+		return errors.WithMessagef(err, "删除已有账户 %s 失败", acc.Name)
+	}
+	err = ks.Rename(tmp, acc.Name)
+	if err != nil {
+		return errors.WithMessagef(err, "账户 %s 已恢复为 %s，改名失败", acc.Name, tmp)
+	}
+	return nil
+}
+
+// storeAccount 将备份中账户的密钥、网络信息及元数据保存为 name 账户
+func storeAccount(ks KeyStore, name string, acc backupAccount) error {
+	if acc.Secret != nil {
+		err := ks.Store(&SecretStoreOpt{Name: name, Content: acc.Secret})
+		if err != nil {
+			return errors.WithMessagef(err, "恢复账户 %s 密钥失败", name)
+		}
+	}
+	if acc.Network != nil {
+		err := ks.Store(&NetworkStoreOpt{Name: name, Content: acc.Network})
+		if err != nil {
+			return errors.WithMessagef(err, "恢复账户 %s 网络信息失败", name)
+		}
+	}
+	if acc.Meta != nil {
+		err := ks.Store(&MetaStoreOpt{Name: name, Content: acc.Meta})
+		if err != nil {
+			return errors.WithMessagef(err, "恢复账户 %s 元数据失败", name)
+		}
+	}
+	return nil
+}
+
+// freeName 生成 name-restored、name-restored-2 …… 中第一个未被占用的名称
+func freeName(exists map[string]bool, name string) string {
+	candidate := name + "-restored"
+	for i := 2; exists[candidate]; i++ {
+		candidate = fmt.Sprintf("%s-restored-%d", name, i)
+	}
+	return candidate
+}
@@ -0,0 +1,80 @@
+package keystore_test
+
+import (
+	"bytes"
+	"testing"
+
+	"bewallet/pkg/keystore"
+	"bewallet/pkg/utils"
+)
+
+func TestBackupRestore(t *testing.T) {
+	src := keystore.NewMemKeyStore()
+	if err := src.Store(&keystore.SecretStoreOpt{Name: "alice", Content: []byte("backup")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.Store(&keystore.NetworkStoreOpt{Name: "alice", Content: []byte("{}")}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := keystore.Backup(src, "backup-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dst, err := keystore.NewFilKeyStore(t.TempDir(), "Passw0rd!x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dst.Store(&keystore.SecretStoreOpt{Name: "alice", Content: []byte("existing")}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keystore.Restore(dst, data, "wrong", keystore.ConflictOverwrite); err == nil {
+		t.Fatal("备份口令错误时应返回错误")
+	}
+
+	result, err := keystore.Restore(dst, data, "backup-password", keystore.ConflictOverwrite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Restored) != 1 || result.Restored[0] != "alice" {
+		t.Fatalf("恢复结果不正确: %+v", result)
+	}
+	sec, err := dst.Load(&keystore.SecretLoadOpt{Name: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(sec, []byte("backup")) {
+		t.Fatalf("覆盖后的密钥为 %q", sec)
+	}
+	list, err := dst.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 {
+		t.Fatalf("覆盖恢复后不应留下临时账户: %v", list)
+	}
+
+	result, err = keystore.Restore(dst, data, "backup-password", keystore.ConflictRename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Renamed["alice"] != "alice-restored" {
+		t.Fatalf("改名恢复结果不正确: %+v", result)
+	}
+}
+
+func TestRestoreRejectsInvalidName(t *testing.T) {
+	plain := []byte(`{"version":1,"accounts":[{"name":"../escape","secret":"ZXNjYXBl"}]}`)
+	data, err := utils.Seal(plain, []byte("backup-password"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	ks, err := keystore.NewFilKeyStore(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keystore.Restore(ks, data, "backup-password", keystore.ConflictSkip); err == nil {
+		t.Fatal("备份中包含非法账户名称时应返回错误")
+	}
+}
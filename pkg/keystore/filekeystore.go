@@ -1,10 +1,12 @@
 package keystore
 
 import (
+	"crypto/subtle"
 	"errors"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"bewallet/pkg/utils"
 )
@@ -24,24 +26,54 @@ var (
 type KeyStore interface {
 	// 密钥持久化 ..
 	Store(opt StoreOpts) error
-	// 密钥加载
+	// 密钥加载，网络信息或元数据不存在时返回 nil, nil，密钥不存在时返回满足 os.IsNotExist 的错误
 	Load(opt LoadOpts) ([]byte, error)
 	// 列出密钥列表
 	List() ([]string, error)
+	// 删除账户的全部数据，密钥数据在删除前被覆写
+	Delete(name string) error
+	// 账户改名，newName 已存在时返回满足 os.IsExist 的错误
+	Rename(name, newName string) error
+	// 归档账户，归档后不再出现在 List 中
+	Archive(name string) error
+}
+
+// Authenticator 可校验口令的密钥存储，用于展示助记词等敏感操作前再次确认身份
+type Authenticator interface {
+	// 口令与密钥存储口令不一致时返回 ErrPassword
+	VerifyPassword(password string) error
 }
 
 // FileKeyStore ..
 type FileKeyStore struct {
 	password string
 	baseDir  string
+	strict   bool
+}
+
+// Option FileKeyStore 参数
+type Option func(fk *FileKeyStore)
+
+// WithStrict 严格模式，拒绝加载组用户或其他用户可以访问的密钥文件，默认仅输出警告
+func WithStrict(strict bool) Option {
+	return func(fk *FileKeyStore) {
+		fk.strict = strict
+	}
 }
 
 // NewFilKeyStore 生成 FileKeyStore 实例
-func NewFilKeyStore(baseDir string, password string) (*FileKeyStore, error) {
+func NewFilKeyStore(baseDir string, password string, opts ...Option) (*FileKeyStore, error) {
 	fk := &FileKeyStore{
 		password: password,
 		baseDir:  baseDir,
 	}
+	for _, o := range opts {
+		o(fk)
+	}
+	err := fk.recoverReplace()
+	if err != nil {
+		return nil, err
+	}
 	if fk.isNew() {
 		err := fk.tag()
 		if err != nil {
@@ -49,7 +81,7 @@ func NewFilKeyStore(baseDir string, password string) (*FileKeyStore, error) {
 		}
 		return fk, nil
 	}
-	err := fk.verify()
+	err = fk.verify()
 	if err != nil {
 		return nil, err
 	}
@@ -66,20 +98,25 @@ func (fk *FileKeyStore) isNew() bool {
 }
 
 func (fk *FileKeyStore) tag() error {
-	err := os.MkdirAll(fk.baseDir, 0766)
+	unlock, err := fk.lock()
 	if err != nil {
 		return err
 	}
+	defer unlock()
 	file := filepath.Join(fk.baseDir, TagFile)
 	enctag, err := fk.encrypt([]byte(Tag))
 	if err != nil {
 		return err
 	}
-	return ioutil.WriteFile(file, enctag, 0666)
+	return writeFileAtomic(file, enctag)
 }
 
 func (fk *FileKeyStore) verify() error {
 	file := filepath.Join(fk.baseDir, TagFile)
+	err := fk.checkPerm(file)
+	if err != nil {
+		return err
+	}
 	enctag, err := ioutil.ReadFile(file)
 	if err != nil {
 		return err
@@ -94,36 +131,142 @@ func (fk *FileKeyStore) verify() error {
 	return ErrPassword
 }
 
+// VerifyPassword 校验口令是否为密钥存储口令
+func (fk *FileKeyStore) VerifyPassword(password string) error {
+	if subtle.ConstantTimeCompare([]byte(password), []byte(fk.password)) != 1 {
+		return ErrPassword
+	}
+	return nil
+}
+
 func (fk *FileKeyStore) encrypt(plain []byte) ([]byte, error) {
-	if len(fk.password) == 0 {
+	return encrypt(fk.password, plain)
+}
+
+func (fk *FileKeyStore) decrypt(data []byte) ([]byte, error) {
+	return decrypt(fk.password, data)
+}
+
+func encrypt(password string, plain []byte) ([]byte, error) {
+	if len(password) == 0 {
 		return plain, nil
 	}
-	secData, err := utils.AESEncrypt(plain, []byte(fk.password))
+	secData, err := utils.Seal(plain, []byte(password))
 	if err != nil {
 		return nil, err
 	}
 	return secData, nil
 }
 
-func (fk *FileKeyStore) decrypt(data []byte) ([]byte, error) {
-	if len(fk.password) == 0 {
+// decrypt 解密数据，兼容读取早期 AES-CFB 格式
+func decrypt(password string, data []byte) ([]byte, error) {
+	if len(password) == 0 {
 		return data, nil
 	}
-	plain, err := utils.AESDecrypt(data, []byte(fk.password))
+	if utils.IsSealed(data) {
+		return utils.Open(data, []byte(password))
+	}
+	plain, err := utils.AESDecrypt(data, []byte(password))
 	if err != nil {
 		return nil, err
 	}
 	return plain, nil
 }
 
+// Migrate 将早期格式的加密文件（.tag、.sec、.net）重新加密为当前格式，返回迁移的文件数
+func (fk *FileKeyStore) Migrate() (int, error) {
+	if len(fk.password) == 0 {
+		return 0, nil
+	}
+	unlock, err := fk.lock()
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+	files, err := fk.files()
+	if err != nil {
+		return 0, err
+	}
+	contents := make(map[string][]byte)
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return 0, err
+		}
+		if utils.IsSealed(data) {
+			continue
+		}
+		plain, err := fk.decrypt(data)
+		if err != nil {
+			return 0, err
+		}
+		enc, err := fk.encrypt(plain)
+		if err != nil {
+			return 0, err
+		}
+		contents[file] = enc
+	}
+	err = fk.replaceFiles(contents)
+	if err != nil {
+		return 0, err
+	}
+	return len(contents), nil
+}
+
+// files 返回 baseDir 下全部加密文件路径（含 .sec 的备份、.meta 及已归档账户的文件）
+func (fk *FileKeyStore) files() ([]string, error) {
+	files := []string{filepath.Join(fk.baseDir, TagFile)}
+	err := filepath.Walk(fk.baseDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		if info.Name() != TagFile && replaceable(info.Name()) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// replaceable 文件是否为修改口令或迁移时需要重新加密的文件
+func replaceable(name string) bool {
+	if name == TagFile {
+		return true
+	}
+	if strings.HasPrefix(name, ".") {
+		return false
+	}
+	for _, suffix := range []string{".sec", ".sec" + BackupSuffix, ".net", ".meta"} {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
 // Store 密钥持久化 ..
+// 文件先写入临时文件再改名替换，覆盖 .sec 文件前保留一份 .bak 备份
 func (fk *FileKeyStore) Store(opt StoreOpts) error {
+	if err := checkName(opt.Identity()); err != nil {
+		return err
+	}
 	ad, err := filepath.Abs(fk.baseDir)
 	if err != nil {
 		return err
 	}
+	unlock, err := fk.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
 	dir := filepath.Join(ad, opt.Identity())
-	err = os.MkdirAll(dir, 0766)
+	err = os.MkdirAll(dir, DirPerm)
 	if err != nil {
 		return err
 	}
@@ -135,35 +278,47 @@ func (fk *FileKeyStore) Store(opt StoreOpts) error {
 		return err
 	}
 
-	err = os.MkdirAll(ad, 0766)
-	if err != nil {
-		return err
-	}
-	err = ioutil.WriteFile(filePath, data, 0666)
-	if err != nil {
-		return err
+	if opt.StoreType() == KeyTypeSecret {
+		err = backupFile(filePath)
+		if err != nil {
+			return err
+		}
 	}
-	return nil
+	return writeFileAtomic(filePath, data)
 }
 
 // Load 密钥加载
+// .sec 文件损坏无法解密时尝试从 .bak 备份读取
 func (fk *FileKeyStore) Load(opt LoadOpts) ([]byte, error) {
 
 	fileName := getFileName(opt.LoadType(), opt.Identity())
 	filePath := filepath.Join(fk.baseDir, opt.Identity(), fileName)
-	data, err := ioutil.ReadFile(filePath)
-	if err != nil {
-		if err == os.ErrNotExist && opt.LoadType() == KeyTypeNetwork {
-			return nil, nil
+	if opt.LoadType() == KeyTypeSecret {
+		if err := fk.checkPerm(filePath); err != nil && !os.IsNotExist(err) {
+			return nil, err
 		}
-		return nil, err
 	}
+	ori, err := fk.load(filePath)
+	if err == nil {
+		return ori, nil
+	}
+	if os.IsNotExist(err) && opt.LoadType() != KeyTypeSecret {
+		return nil, nil
+	}
+	if opt.LoadType() == KeyTypeSecret && !os.IsNotExist(err) {
+		if bak, berr := fk.load(filePath + BackupSuffix); berr == nil {
+			return bak, nil
+		}
+	}
+	return nil, err
+}
 
-	ori, err := fk.decrypt(data)
+func (fk *FileKeyStore) load(filePath string) ([]byte, error) {
+	data, err := ioutil.ReadFile(filePath)
 	if err != nil {
 		return nil, err
 	}
-	return ori, nil
+	return fk.decrypt(data)
 }
 
 // List 返回已存储的密钥列表
@@ -173,12 +328,13 @@ func (fk *FileKeyStore) List() ([]string, error) {
 	if err != nil {
 		return nil, err
 	}
+	defer f.Close()
 	files, err := f.Readdir(-1)
 	if err != nil {
 		return nil, err
 	}
 	for _, f := range files {
-		if !f.IsDir() {
+		if !f.IsDir() || strings.HasPrefix(f.Name(), ".") {
 			continue
 		}
 		list = append(list, f.Name())
@@ -198,6 +354,8 @@ func getFileName(keyType, name string) string {
 		fileName = name + ".sec"
 	case KeyTypeNetwork:
 		fileName = name + ".net"
+	case KeyTypeMeta:
+		fileName = name + ".meta"
 	}
 	return fileName
 }
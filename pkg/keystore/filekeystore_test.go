@@ -0,0 +1,109 @@
+package keystore_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"bewallet/pkg/keystore"
+	"bewallet/pkg/keystore/kstest"
+	"bewallet/pkg/utils"
+)
+
+func TestFileKeyStore(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		password string
+	}{
+		{"plain", ""},
+		{"encrypted", "Passw0rd!x"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			fk, err := keystore.NewFilKeyStore(t.TempDir(), tc.password)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := kstest.TestKeyStore(fk); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestFileKeyStoreWrongPassword(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := keystore.NewFilKeyStore(dir, "Passw0rd!x"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keystore.NewFilKeyStore(dir, "wrong"); err != keystore.ErrPassword {
+		t.Fatalf("口令错误时应返回 ErrPassword，实际为 %v", err)
+	}
+}
+
+func TestFileKeyStoreMigrateLegacy(t *testing.T) {
+	dir := t.TempDir()
+	fk, err := keystore.NewFilKeyStore(dir, "Passw0rd!x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fk.Store(&keystore.SecretStoreOpt{Name: "alice", Content: []byte("alice")}); err != nil {
+		t.Fatal(err)
+	}
+	// 将 .tag 及 .sec 改写为早期 AES-CFB 格式
+	legacy := []string{}
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		if info.Name() == keystore.TagFile || strings.HasSuffix(path, ".sec") {
+			legacy = append(legacy, path)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range legacy {
+		plain := []byte(keystore.Tag)
+		if strings.HasSuffix(file, ".sec") {
+			plain = []byte("alice")
+		}
+		data, err := utils.AESEncrypt(plain, []byte("Passw0rd!x"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(file, data, keystore.FilePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fk, err = keystore.NewFilKeyStore(dir, "Passw0rd!x")
+	if err != nil {
+		t.Fatal("应能打开早期格式的密钥缓存目录:", err)
+	}
+	data, err := fk.Load(&keystore.SecretLoadOpt{Name: "alice"})
+	if err != nil || string(data) != "alice" {
+		t.Fatalf("读取早期格式的密钥失败: %q %v", data, err)
+	}
+	n, err := fk.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != len(legacy) {
+		t.Fatalf("应迁移 %d 个文件，实际为 %d", len(legacy), n)
+	}
+	for _, file := range legacy {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !utils.IsSealed(data) {
+			t.Fatalf("%s 未迁移为当前格式", file)
+		}
+	}
+	if _, err := keystore.NewFilKeyStore(dir, "wrong"); err != keystore.ErrPassword {
+		t.Fatalf("口令错误时应返回 ErrPassword，实际为 %v", err)
+	}
+}
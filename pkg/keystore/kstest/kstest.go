@@ -0,0 +1,256 @@
+// Package kstest 提供 keystore.KeyStore 实现的一致性检查
+package kstest
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"bewallet/pkg/keystore"
+)
+
+// TestKeyStore 检查 KeyStore 实现是否与 FileKeyStore 行为一致，ks 须为空的存储。
+// 检查通过返回 nil，否则返回第一个不一致之处，可在各实现的测试中调用：
+//
+//	if err := kstest.TestKeyStore(keystore.NewMemKeyStore()); err != nil {
+//		t.Fatal(err)
+//	}
+func TestKeyStore(ks keystore.KeyStore) error {
+	checks := []struct {
+		name string
+		fn   func(keystore.KeyStore) error
+	}{
+		{"empty", testEmpty},
+		{"missing", testMissing},
+		{"invalid", testInvalidName},
+		{"roundtrip", testRoundTrip},
+		{"overwrite", testOverwrite},
+		{"list", testList},
+		{"isolation", testIsolation},
+		{"concurrent", testConcurrent},
+		{"delete", testDelete},
+		{"rename", testRename},
+		{"archive", testArchive},
+	}
+	for _, c := range checks {
+		if err := c.fn(ks); err != nil {
+			return errors.WithMessagef(err, "kstest %s", c.name)
+		}
+	}
+	return nil
+}
+
+func testEmpty(ks keystore.KeyStore) error {
+	list, err := ks.List()
+	if err != nil {
+		return err
+	}
+	if len(list) != 0 {
+		return errors.Errorf("新建存储的 List 应为空，实际为 %v", list)
+	}
+	return nil
+}
+
+func testMissing(ks keystore.KeyStore) error {
+	_, err := ks.Load(&keystore.SecretLoadOpt{Name: "missing"})
+	if err == nil || !os.IsNotExist(errors.Cause(err)) {
+		return errors.Errorf("加载不存在的密钥应返回 os.ErrNotExist，实际为 %v", err)
+	}
+	data, err := ks.Load(&keystore.NetworkLoadOpt{Name: "missing"})
+	if err != nil || data != nil {
+		return errors.Errorf("加载不存在的网络信息应返回 nil, nil，实际为 %q, %v", data, err)
+	}
+	data, err = ks.Load(&keystore.MetaLoadOpt{Name: "missing"})
+	if err != nil || data != nil {
+		return errors.Errorf("加载不存在的元数据应返回 nil, nil，实际为 %q, %v", data, err)
+	}
+	return nil
+}
+
+func testInvalidName(ks keystore.KeyStore) error {
+	for _, name := range []string{"", "../escape", "a/b", ".hidden"} {
+		if err := ks.Store(&keystore.SecretStoreOpt{Name: name, Content: []byte("invalid")}); err == nil {
+			return errors.Errorf("保存非法名称 %q 的账户应返回错误", name)
+		}
+	}
+	return testEmpty(ks)
+}
+
+func testRoundTrip(ks keystore.KeyStore) error {
+	sec := []byte(`{"Key":"c2VjcmV0"}`)
+	net := []byte(`{"net":{}}`)
+	if err := ks.Store(&keystore.SecretStoreOpt{Name: "roundtrip", Content: sec}); err != nil {
+		return err
+	}
+	data, err := ks.Load(&keystore.NetworkLoadOpt{Name: "roundtrip"})
+	if err != nil || data != nil {
+		return errors.Errorf("仅保存密钥时网络信息应为 nil, nil，实际为 %q, %v", data, err)
+	}
+	if err := ks.Store(&keystore.NetworkStoreOpt{Name: "roundtrip", Content: net}); err != nil {
+		return err
+	}
+	if err := expect(ks, &keystore.SecretLoadOpt{Name: "roundtrip"}, sec); err != nil {
+		return err
+	}
+	return expect(ks, &keystore.NetworkLoadOpt{Name: "roundtrip"}, net)
+}
+
+func testOverwrite(ks keystore.KeyStore) error {
+	for _, content := range []string{"first", "second"} {
+		if err := ks.Store(&keystore.SecretStoreOpt{Name: "overwrite", Content: []byte(content)}); err != nil {
+			return err
+		}
+		if err := expect(ks, &keystore.SecretLoadOpt{Name: "overwrite"}, []byte(content)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func testList(ks keystore.KeyStore) error {
+	if err := ks.Store(&keystore.NetworkStoreOpt{Name: "netonly", Content: []byte("{}")}); err != nil {
+		return err
+	}
+	list, err := ks.List()
+	if err != nil {
+		return err
+	}
+	count := map[string]int{}
+	for _, name := range list {
+		count[name]++
+	}
+	for _, name := range []string{"roundtrip", "overwrite", "netonly"} {
+		if count[name] != 1 {
+			return errors.Errorf("List 中 %s 应出现 1 次，实际为 %d 次：%v", name, count[name], list)
+		}
+	}
+	return nil
+}
+
+func testIsolation(ks keystore.KeyStore) error {
+	content := []byte("isolation")
+	if err := ks.Store(&keystore.SecretStoreOpt{Name: "isolation", Content: content}); err != nil {
+		return err
+	}
+	content[0] = 'X'
+	data, err := ks.Load(&keystore.SecretLoadOpt{Name: "isolation"})
+	if err != nil {
+		return err
+	}
+	data[1] = 'X'
+	return expect(ks, &keystore.SecretLoadOpt{Name: "isolation"}, []byte("isolation"))
+}
+
+func testConcurrent(ks keystore.KeyStore) error {
+	const n = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			name := fmt.Sprintf("concurrent%d", i)
+			content := []byte(name)
+			if err := ks.Store(&keystore.SecretStoreOpt{Name: name, Content: content}); err != nil {
+				errs <- err
+				return
+			}
+			errs <- expect(ks, &keystore.SecretLoadOpt{Name: name}, content)
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func testDelete(ks keystore.KeyStore) error {
+	if err := ks.Store(&keystore.SecretStoreOpt{Name: "delete", Content: []byte("delete")}); err != nil {
+		return err
+	}
+	if err := ks.Delete("delete"); err != nil {
+		return err
+	}
+	if err := expectMissing(ks, "delete"); err != nil {
+		return err
+	}
+	if err := ks.Delete("delete"); err == nil || !os.IsNotExist(errors.Cause(err)) {
+		return errors.Errorf("删除不存在的账户应返回 os.ErrNotExist，实际为 %v", err)
+	}
+	return nil
+}
+
+func testRename(ks keystore.KeyStore) error {
+	sec, net, meta := []byte("rename"), []byte("{}"), []byte(`{"ID":"rename"}`)
+	if err := ks.Store(&keystore.SecretStoreOpt{Name: "rename", Content: sec}); err != nil {
+		return err
+	}
+	if err := ks.Store(&keystore.NetworkStoreOpt{Name: "rename", Content: net}); err != nil {
+		return err
+	}
+	if err := ks.Store(&keystore.MetaStoreOpt{Name: "rename", Content: meta}); err != nil {
+		return err
+	}
+	if err := ks.Rename("rename", "roundtrip"); err == nil || !os.IsExist(errors.Cause(err)) {
+		return errors.Errorf("改名为已存在的账户应返回 os.ErrExist，实际为 %v", err)
+	}
+	if err := ks.Rename("rename", "renamed"); err != nil {
+		return err
+	}
+	if err := expectMissing(ks, "rename"); err != nil {
+		return err
+	}
+	if err := expect(ks, &keystore.SecretLoadOpt{Name: "renamed"}, sec); err != nil {
+		return err
+	}
+	if err := expect(ks, &keystore.NetworkLoadOpt{Name: "renamed"}, net); err != nil {
+		return err
+	}
+	return expect(ks, &keystore.MetaLoadOpt{Name: "renamed"}, meta)
+}
+
+func testArchive(ks keystore.KeyStore) error {
+	if err := ks.Store(&keystore.SecretStoreOpt{Name: "archive", Content: []byte("archive")}); err != nil {
+		return err
+	}
+	if err := ks.Archive("archive"); err != nil {
+		return err
+	}
+	return expectMissing(ks, "archive")
+}
+
+// expectMissing 账户不应出现在 List 中，且其密钥无法加载
+func expectMissing(ks keystore.KeyStore, name string) error {
+	list, err := ks.List()
+	if err != nil {
+		return err
+	}
+	for _, n := range list {
+		if n == name {
+			return errors.Errorf("List 中不应再出现 %s：%v", name, list)
+		}
+	}
+	_, err = ks.Load(&keystore.SecretLoadOpt{Name: name})
+	if err == nil || !os.IsNotExist(errors.Cause(err)) {
+		return errors.Errorf("加载 %s 的密钥应返回 os.ErrNotExist，实际为 %v", name, err)
+	}
+	return nil
+}
+
+func expect(ks keystore.KeyStore, opt keystore.LoadOpts, want []byte) error {
+	data, err := ks.Load(opt)
+	if err != nil {
+		return errors.WithMessagef(err, "加载 %s %s 失败", opt.LoadType(), opt.Identity())
+	}
+	if !bytes.Equal(data, want) {
+		return errors.Errorf("加载 %s %s 得到 %q，应为 %q", opt.LoadType(), opt.Identity(), data, want)
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package keystore
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ArchiveDir 已归档账户所在目录（位于 baseDir 下）
+const ArchiveDir = ".archive"
+
+// Delete 删除账户目录，.sec 及其备份在删除前以随机数据覆写
+func (fk *FileKeyStore) Delete(name string) error {
+	if err := checkName(name); err != nil {
+		return err
+	}
+	unlock, err := fk.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	dir := filepath.Join(fk.baseDir, name)
+	if _, err := os.Stat(dir); err != nil {
+		return err
+	}
+	secFile := filepath.Join(dir, getFileName(KeyTypeSecret, name))
+	for _, file := range []string{secFile, secFile + BackupSuffix} {
+		err = shred(file)
+		if err != nil && !os.IsNotExist(err) {
+			return errors.WithMessagef(err, "覆写文件 %s 失败", file)
+		}
+	}
+	err = os.RemoveAll(dir)
+	if err != nil {
+		return err
+	}
+	return syncDir(fk.baseDir)
+}
+
+// Rename 账户改名，同时修改账户目录及其中文件的名称
+func (fk *FileKeyStore) Rename(name, newName string) error {
+	if err := checkName(name); err != nil {
+		return err
+	}
+	if err := checkName(newName); err != nil {
+		return err
+	}
+	unlock, err := fk.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	dir := filepath.Join(fk.baseDir, name)
+	newDir := filepath.Join(fk.baseDir, newName)
+	if _, err := os.Stat(dir); err != nil {
+		return err
+	}
+	if _, err := os.Stat(newDir); err == nil {
+		return &os.PathError{Op: "rename", Path: newDir, Err: os.ErrExist}
+	}
+
+	secFile := getFileName(KeyTypeSecret, name)
+	newSecFile := getFileName(KeyTypeSecret, newName)
+	renames := [][2]string{
+		{secFile, newSecFile},
+		{secFile + BackupSuffix, newSecFile + BackupSuffix},
+		{getFileName(KeyTypeNetwork, name), getFileName(KeyTypeNetwork, newName)},
+		{getFileName(KeyTypeMeta, name), getFileName(KeyTypeMeta, newName)},
+	}
+	done := [][2]string{}
+	rollback := func() {
+		for i := len(done) - 1; i >= 0; i-- {
+			os.Rename(filepath.Join(dir, done[i][1]), filepath.Join(dir, done[i][0]))
+		}
+	}
+	for _, r := range renames {
+		err = os.Rename(filepath.Join(dir, r[0]), filepath.Join(dir, r[1]))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			rollback()
+			return err
+		}
+		done = append(done, r)
+	}
+	err = os.Rename(dir, newDir)
+	if err != nil {
+		rollback()
+		return err
+	}
+	return syncDir(fk.baseDir)
+}
+
+// Archive 将账户目录移动到 baseDir/.archive/<name>.<时间戳> 下
+func (fk *FileKeyStore) Archive(name string) error {
+	if err := checkName(name); err != nil {
+		return err
+	}
+	unlock, err := fk.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	dir := filepath.Join(fk.baseDir, name)
+	if _, err := os.Stat(dir); err != nil {
+		return err
+	}
+	archiveDir := filepath.Join(fk.baseDir, ArchiveDir)
+	err = os.MkdirAll(archiveDir, DirPerm)
+	if err != nil {
+		return err
+	}
+	target := filepath.Join(archiveDir, fmt.Sprintf("%s.%d", name, time.Now().UnixNano()))
+	err = os.Rename(dir, target)
+	if err != nil {
+		return err
+	}
+	return syncDir(fk.baseDir)
+}
+
+// checkName 账户名称同时用作目录名和文件名，不能为空、不能包含路径分隔符或以 . 开头
+func checkName(name string) error {
+	if len(name) == 0 || strings.HasPrefix(name, ".") || strings.ContainsAny(name, `/\`) {
+		return errors.Errorf("账户名称 %q 非法", name)
+	}
+	return nil
+}
+
+// shred 以随机数据覆写文件内容并落盘
+func shred(file string) error {
+	f, err := os.OpenFile(file, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	_, err = io.CopyN(f, rand.Reader, info.Size())
+	if err != nil {
+		return err
+	}
+	return f.Sync()
+}
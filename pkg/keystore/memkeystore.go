@@ -0,0 +1,129 @@
+package keystore
+
+import (
+	"os"
+	"sort"
+	"sync"
+)
+
+// MemKeyStore 内存密钥存储，用于测试及临时钱包，数据不落盘
+type MemKeyStore struct {
+	mu       sync.RWMutex
+	data     map[string]map[string][]byte // 标识 -> 存储类别 -> 数据
+	archived []map[string][]byte
+}
+
+// NewMemKeyStore 生成 MemKeyStore 实例
+func NewMemKeyStore() *MemKeyStore {
+	return &MemKeyStore{
+		data: make(map[string]map[string][]byte),
+	}
+}
+
+// Store 密钥持久化 ..
+func (mk *MemKeyStore) Store(opt StoreOpts) error {
+	if err := checkName(opt.Identity()); err != nil {
+		return err
+	}
+	mk.mu.Lock()
+	defer mk.mu.Unlock()
+	items, ok := mk.data[opt.Identity()]
+	if !ok {
+		items = make(map[string][]byte)
+		mk.data[opt.Identity()] = items
+	}
+	items[opt.StoreType()] = copyBytes(opt.Data())
+	return nil
+}
+
+// VerifyPassword MemKeyStore 不使用口令，仅接受空口令
+func (mk *MemKeyStore) VerifyPassword(password string) error {
+	if len(password) != 0 {
+		return ErrPassword
+	}
+	return nil
+}
+
+// Load 密钥加载，与 FileKeyStore 一致：网络信息或元数据不存在时返回 nil，密钥不存在时返回 os.ErrNotExist
+func (mk *MemKeyStore) Load(opt LoadOpts) ([]byte, error) {
+	mk.mu.RLock()
+	defer mk.mu.RUnlock()
+	data, ok := mk.data[opt.Identity()][opt.LoadType()]
+	if !ok {
+		if opt.LoadType() != KeyTypeSecret {
+			return nil, nil
+		}
+		return nil, &os.PathError{Op: "load", Path: getFileName(opt.LoadType(), opt.Identity()), Err: os.ErrNotExist}
+	}
+	return copyBytes(data), nil
+}
+
+// List 返回已存储的密钥列表
+func (mk *MemKeyStore) List() ([]string, error) {
+	mk.mu.RLock()
+	defer mk.mu.RUnlock()
+	list := make([]string, 0, len(mk.data))
+	for name := range mk.data {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+	return list, nil
+}
+
+// Delete 删除账户的全部数据
+func (mk *MemKeyStore) Delete(name string) error {
+	mk.mu.Lock()
+	defer mk.mu.Unlock()
+	items, ok := mk.data[name]
+	if !ok {
+		return &os.PathError{Op: "delete", Path: name, Err: os.ErrNotExist}
+	}
+	for _, data := range items {
+		for i := range data {
+			data[i] = 0
+		}
+	}
+	delete(mk.data, name)
+	return nil
+}
+
+// Rename 账户改名
+func (mk *MemKeyStore) Rename(name, newName string) error {
+	if err := checkName(newName); err != nil {
+		return err
+	}
+	mk.mu.Lock()
+	defer mk.mu.Unlock()
+	items, ok := mk.data[name]
+	if !ok {
+		return &os.PathError{Op: "rename", Path: name, Err: os.ErrNotExist}
+	}
+	if _, ok := mk.data[newName]; ok {
+		return &os.PathError{Op: "rename", Path: newName, Err: os.ErrExist}
+	}
+	mk.data[newName] = items
+	delete(mk.data, name)
+	return nil
+}
+
+// Archive 归档账户
+func (mk *MemKeyStore) Archive(name string) error {
+	mk.mu.Lock()
+	defer mk.mu.Unlock()
+	items, ok := mk.data[name]
+	if !ok {
+		return &os.PathError{Op: "archive", Path: name, Err: os.ErrNotExist}
+	}
+	mk.archived = append(mk.archived, items)
+	delete(mk.data, name)
+	return nil
+}
+
+func copyBytes(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	c := make([]byte, len(b))
+	copy(c, b)
+	return c
+}
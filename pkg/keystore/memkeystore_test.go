@@ -0,0 +1,14 @@
+package keystore_test
+
+import (
+	"testing"
+
+	"bewallet/pkg/keystore"
+	"bewallet/pkg/keystore/kstest"
+)
+
+func TestMemKeyStore(t *testing.T) {
+	if err := kstest.TestKeyStore(keystore.NewMemKeyStore()); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -17,6 +17,7 @@ type LoadOpts interface {
 const (
 	KeyTypeSecret  = "secret"
 	KeyTypeNetwork = "network"
+	KeyTypeMeta    = "meta"
 )
 
 // SecretStoreOpt 密钥存储
@@ -61,6 +62,27 @@ func (no *NetworkStoreOpt) Identity() string {
 	return no.Name
 }
 
+// MetaStoreOpt 账户元数据存储
+type MetaStoreOpt struct {
+	Content []byte
+	Name    string
+}
+
+// Data 存储内容
+func (mo *MetaStoreOpt) Data() []byte {
+	return mo.Content
+}
+
+// StoreType 存储数据类别
+func (mo *MetaStoreOpt) StoreType() string {
+	return KeyTypeMeta
+}
+
+// Identity 存储数据标识
+func (mo *MetaStoreOpt) Identity() string {
+	return mo.Name
+}
+
 type SecretLoadOpt struct {
 	Name string
 }
@@ -84,3 +106,15 @@ func (nl *NetworkLoadOpt) Identity() string {
 func (nl *NetworkLoadOpt) LoadType() string {
 	return KeyTypeNetwork
 }
+
+type MetaLoadOpt struct {
+	Name string
+}
+
+func (ml *MetaLoadOpt) Identity() string {
+	return ml.Name
+}
+
+func (ml *MetaLoadOpt) LoadType() string {
+	return KeyTypeMeta
+}
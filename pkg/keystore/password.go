@@ -0,0 +1,235 @@
+package keystore
+
+import (
+	"crypto/subtle"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// rename 替换文件时使用的改名函数，测试中用于模拟改名失败
+var rename = os.Rename
+
+const (
+	newFileSuffix = ".new"
+	oldFileSuffix = ".old"
+	journalFile   = ".replace" // 批量替换文件的日志，位于 baseDir 下
+)
+
+// ChangePassword 修改口令，使用新口令重新加密 baseDir 下的 .tag 及全部 .sec、.net 文件。
+// 任一文件处理失败时已替换的文件会被回滚，保证全部文件仍使用原口令
+func (fk *FileKeyStore) ChangePassword(oldPassword, newPassword string) error {
+	if subtle.ConstantTimeCompare([]byte(oldPassword), []byte(fk.password)) != 1 {
+		return ErrPassword
+	}
+	unlock, err := fk.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	files, err := fk.files()
+	if err != nil {
+		return err
+	}
+	contents := make(map[string][]byte, len(files))
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		plain, err := decrypt(oldPassword, data)
+		if err != nil {
+			return errors.WithMessagef(err, "解密文件 %s 失败", file)
+		}
+		enc, err := encrypt(newPassword, plain)
+		if err != nil {
+			return err
+		}
+		contents[file] = enc
+	}
+	err = fk.replaceFiles(contents)
+	if err != nil {
+		return err
+	}
+	fk.password = newPassword
+	return nil
+}
+
+// replaceFiles 以尽量原子的方式整体替换多个文件：先全部写入 .new 临时文件，再写入记录待替换文件的日志，
+// 然后逐个将原文件改名为 .old 并换入新文件，全部完成后删除 .old 文件及日志。
+// 中途失败时恢复已替换的文件，未能全部恢复时保留日志及 .new 文件；
+// 进程崩溃或未能恢复时由 recoverReplace 在下次打开时根据日志继续完成替换
+func (fk *FileKeyStore) replaceFiles(contents map[string][]byte) (err error) {
+	written := []string{}
+	defer func() {
+		for _, file := range written {
+			os.Remove(file + newFileSuffix)
+		}
+	}()
+	for file, data := range contents {
+		err = writeFileAtomic(file+newFileSuffix, data)
+		if err != nil {
+			return err
+		}
+		written = append(written, file)
+	}
+	err = fk.writeJournal(written)
+	if err != nil {
+		return err
+	}
+
+	replaced := []string{}
+	swapped := map[string]bool{}
+	defer func() {
+		if err != nil {
+			restored := true
+			for _, file := range replaced {
+				// 换回 .new 文件，恢复失败时仍可按日志继续完成替换
+				if swapped[file] {
+					if rerr := rename(file, file+newFileSuffix); rerr != nil {
+						restored = false
+						continue
+					}
+				}
+				if rerr := rename(file+oldFileSuffix, file); rerr != nil {
+					restored = false
+				}
+			}
+			// 未能全部恢复时保留日志及 .new 文件，下次打开时继续完成替换，避免新旧文件混用
+			if restored {
+				fk.removeJournal()
+			} else {
+				written = nil
+			}
+			return
+		}
+		for _, file := range replaced {
+			os.Remove(file + oldFileSuffix)
+		}
+		fk.removeJournal()
+	}()
+	for _, file := range written {
+		err = rename(file, file+oldFileSuffix)
+		if err != nil {
+			return errors.Wrapf(err, "备份文件 %s 失败", file)
+		}
+		replaced = append(replaced, file)
+		err = rename(file+newFileSuffix, file)
+		if err != nil {
+			return errors.Wrapf(err, "替换文件 %s 失败", file)
+		}
+		swapped[file] = true
+	}
+	for _, file := range replaced {
+		err = syncDir(filepath.Dir(file))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeJournal 写入待替换文件列表（相对于 baseDir 的路径，每行一个）
+func (fk *FileKeyStore) writeJournal(files []string) error {
+	list := make([]string, 0, len(files))
+	for _, file := range files {
+		rel, err := filepath.Rel(fk.baseDir, file)
+		if err != nil {
+			return err
+		}
+		list = append(list, filepath.ToSlash(rel))
+	}
+	sort.Strings(list)
+	return writeFileAtomic(filepath.Join(fk.baseDir, journalFile), []byte(strings.Join(list, "\n")))
+}
+
+func (fk *FileKeyStore) removeJournal() error {
+	err := os.Remove(filepath.Join(fk.baseDir, journalFile))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return syncDir(fk.baseDir)
+}
+
+// recoverReplace 处理上次 replaceFiles 中断留下的文件。日志存在时新文件已全部写入，
+// 将剩余的 .new 文件换入并删除 .old 文件，使全部文件统一使用新内容（新旧文件均缺失时保留 .old 文件的内容）；
+// 日志不存在时替换尚未开始，删除残留的 .new 文件，原文件缺失时由 .old 文件恢复
+func (fk *FileKeyStore) recoverReplace() error {
+	if _, err := os.Stat(fk.baseDir); os.IsNotExist(err) {
+		return nil
+	}
+	unlock, err := fk.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	data, err := ioutil.ReadFile(filepath.Join(fk.baseDir, journalFile))
+	if err == nil {
+		return fk.rollForward(string(data))
+	}
+	if !os.IsNotExist(err) {
+		return err
+	}
+	return filepath.Walk(fk.baseDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		switch name := info.Name(); {
+		case strings.HasSuffix(name, newFileSuffix) && replaceable(strings.TrimSuffix(name, newFileSuffix)):
+			return os.Remove(path)
+		case strings.HasSuffix(name, oldFileSuffix) && replaceable(strings.TrimSuffix(name, oldFileSuffix)):
+			file := strings.TrimSuffix(path, oldFileSuffix)
+			if _, err := os.Stat(file); os.IsNotExist(err) {
+				return os.Rename(path, file)
+			}
+		}
+		return nil
+	})
+}
+
+// rollForward 按日志完成中断的替换
+func (fk *FileKeyStore) rollForward(journal string) error {
+	files := []string{}
+	for _, rel := range strings.Split(journal, "\n") {
+		if len(rel) == 0 {
+			continue
+		}
+		file := filepath.Join(fk.baseDir, filepath.FromSlash(rel))
+		if !strings.HasPrefix(file, filepath.Clean(fk.baseDir)+string(filepath.Separator)) {
+			return errors.Errorf("替换日志中的文件 %s 不在密钥缓存目录中", rel)
+		}
+		files = append(files, file)
+	}
+	for _, file := range files {
+		err := os.Rename(file+newFileSuffix, file)
+		if err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "替换文件 %s 失败", file)
+		}
+	}
+	for _, file := range files {
+		err := syncDir(filepath.Dir(file))
+		if err != nil {
+			return err
+		}
+	}
+	for _, file := range files {
+		var err error
+		if _, serr := os.Stat(file); os.IsNotExist(serr) {
+			// 新文件已丢失，.old 文件是唯一的副本
+			err = os.Rename(file+oldFileSuffix, file)
+			if err == nil {
+				err = syncDir(filepath.Dir(file))
+			}
+		} else {
+			err = os.Remove(file + oldFileSuffix)
+		}
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return fk.removeJournal()
+}
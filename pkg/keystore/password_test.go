@@ -0,0 +1,212 @@
+package keystore
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// interruptedChange 模拟修改口令时进程在替换第一个文件后崩溃，journal 为 false 时模拟写入日志前崩溃
+func interruptedChange(t *testing.T, dir string, journal bool) {
+	fk, err := NewFilKeyStore(dir, "old-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"alice", "bob"} {
+		if err := fk.Store(&SecretStoreOpt{Name: name, Content: []byte(name)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files, err := fk.files()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		plain, err := decrypt("old-password", data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		enc, err := encrypt("new-password", plain)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := writeFileAtomic(file+newFileSuffix, enc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !journal {
+		return
+	}
+	if err := fk.writeJournal(files); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(files[0], files[0]+oldFileSuffix); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(files[0]+newFileSuffix, files[0]); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func checkLeftovers(t *testing.T, dir string) {
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		switch filepath.Ext(path) {
+		case newFileSuffix, oldFileSuffix:
+			t.Errorf("残留文件 %s", path)
+		}
+		if info.Name() == journalFile {
+			t.Errorf("残留日志 %s", path)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRecoverInterruptedPasswordChange(t *testing.T) {
+	dir := t.TempDir()
+	interruptedChange(t, dir, true)
+
+	if _, err := NewFilKeyStore(dir, "old-password"); err != ErrPassword {
+		t.Fatalf("完成替换后原口令应失效，实际为 %v", err)
+	}
+	fk, err := NewFilKeyStore(dir, "new-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"alice", "bob"} {
+		data, err := fk.Load(&SecretLoadOpt{Name: name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != name {
+			t.Fatalf("账户 %s 的密钥为 %q", name, data)
+		}
+	}
+	checkLeftovers(t, dir)
+}
+
+func TestDiscardUnjournaledPasswordChange(t *testing.T) {
+	dir := t.TempDir()
+	interruptedChange(t, dir, false)
+
+	fk, err := NewFilKeyStore(dir, "old-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := fk.Load(&SecretLoadOpt{Name: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "alice" {
+		t.Fatalf("账户 alice 的密钥为 %q", data)
+	}
+	checkLeftovers(t, dir)
+}
+
+func TestChangePassword(t *testing.T) {
+	dir := t.TempDir()
+	fk, err := NewFilKeyStore(dir, "old-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fk.Store(&SecretStoreOpt{Name: "alice", Content: []byte("alice")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := fk.ChangePassword("wrong", "new-password"); err != ErrPassword {
+		t.Fatalf("原口令错误时应返回 ErrPassword，实际为 %v", err)
+	}
+	if err := fk.ChangePassword("old-password", "new-password"); err != nil {
+		t.Fatal(err)
+	}
+	checkLeftovers(t, dir)
+	if _, err := NewFilKeyStore(dir, "new-password"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestChangePasswordRollbackFails(t *testing.T) {
+	dir := t.TempDir()
+	fk, err := NewFilKeyStore(dir, "old-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"alice", "bob"} {
+		if err := fk.Store(&SecretStoreOpt{Name: name, Content: []byte(name)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 第二个文件换入新文件失败，回滚时由 .old 恢复同样失败
+	swaps := 0
+	rename = func(from, to string) error {
+		switch filepath.Ext(from) {
+		case newFileSuffix:
+			swaps++
+			if swaps == 2 {
+				return os.ErrPermission
+			}
+		case oldFileSuffix:
+			return os.ErrPermission
+		}
+		return os.Rename(from, to)
+	}
+	defer func() { rename = os.Rename }()
+	if err := fk.ChangePassword("old-password", "new-password"); err == nil {
+		t.Fatal("替换失败时应返回错误")
+	}
+	rename = os.Rename
+
+	if _, err := os.Stat(filepath.Join(dir, journalFile)); err != nil {
+		t.Fatal("未能恢复时应保留日志", err)
+	}
+	fk, err = NewFilKeyStore(dir, "new-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"alice", "bob"} {
+		data, err := fk.Load(&SecretLoadOpt{Name: name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != name {
+			t.Fatalf("账户 %s 的密钥为 %q", name, data)
+		}
+	}
+	checkLeftovers(t, dir)
+}
+
+func TestRollForwardKeepsOnlyCopy(t *testing.T) {
+	dir := t.TempDir()
+	interruptedChange(t, dir, true)
+	files, err := (&FileKeyStore{baseDir: dir}).files()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 第二个文件已改名为 .old，但新文件丢失
+	if err := os.Rename(files[1], files[1]+oldFileSuffix); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(files[1] + newFileSuffix); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewFilKeyStore(dir, "new-password"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(files[1])
+	if err != nil {
+		t.Fatal("新文件缺失时应由 .old 文件恢复", err)
+	}
+	if _, err := decrypt("old-password", data); err != nil {
+		t.Fatal(err)
+	}
+	checkLeftovers(t, dir)
+}
@@ -0,0 +1,105 @@
+package keystore
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/pkg/errors"
+)
+
+// 密钥缓存目录及文件权限
+const (
+	DirPerm  os.FileMode = 0700
+	FilePerm os.FileMode = 0600
+)
+
+// 错误
+var (
+	ErrInsecurePermission = errors.New("文件权限不安全，组用户或其他用户可以访问")
+)
+
+// PermissionIssue 权限问题
+type PermissionIssue struct {
+	Path     string
+	Mode     os.FileMode
+	Expected os.FileMode
+	Fixed    bool
+	Symlink  bool // 符号链接，不自动修复
+}
+
+func (pi PermissionIssue) String() string {
+	if pi.Symlink {
+		return fmt.Sprintf("%s: 符号链接，密钥缓存目录中不应包含符号链接，请手动检查（未修复）", pi.Path)
+	}
+	status := "未修复"
+	if pi.Fixed {
+		status = "已修复"
+	}
+	return fmt.Sprintf("%s: %#o，应为 %#o（%s）", pi.Path, pi.Mode.Perm(), pi.Expected, status)
+}
+
+// insecure 组用户或其他用户拥有任何权限即视为不安全，windows 下不检查
+func insecure(mode os.FileMode) bool {
+	return runtime.GOOS != "windows" && mode.Perm()&0077 != 0
+}
+
+// checkPerm 检查文件及其所在目录的权限，严格模式下拒绝加载，否则仅输出警告
+func (fk *FileKeyStore) checkPerm(file string) error {
+	for _, p := range []string{filepath.Dir(file), file} {
+		info, err := os.Stat(p)
+		if err != nil {
+			return err
+		}
+		if !insecure(info.Mode()) {
+			continue
+		}
+		if fk.strict {
+			return errors.WithMessagef(ErrInsecurePermission, "%s 权限为 %#o", p, info.Mode().Perm())
+		}
+		log.Printf("警告: %s 权限为 %#o，组用户或其他用户可以访问，可执行 doctor 修复", p, info.Mode().Perm())
+	}
+	return nil
+}
+
+// Doctor 检查 baseDir 下全部目录和文件的权限，fix 为 true 时将其修正为 DirPerm、FilePerm。
+// 符号链接只报告不修复，避免修改链接目标的权限
+func Doctor(baseDir string, fix bool) ([]PermissionIssue, error) {
+	issues := []PermissionIssue{}
+	err := filepath.Walk(baseDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			issues = append(issues, PermissionIssue{Path: path, Mode: info.Mode(), Symlink: true})
+			return nil
+		}
+		if !insecure(info.Mode()) {
+			return nil
+		}
+		expected := FilePerm
+		if info.IsDir() {
+			expected = DirPerm
+		}
+		issue := PermissionIssue{
+			Path:     path,
+			Mode:     info.Mode(),
+			Expected: expected,
+		}
+		if fix {
+			err = os.Chmod(path, expected)
+			if err != nil {
+				return errors.Wrapf(err, "修改 %s 权限失败", path)
+			}
+			issue.Fixed = true
+		}
+		issues = append(issues, issue)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return issues, nil
+}
@@ -0,0 +1,72 @@
+package keystore_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"bewallet/pkg/keystore"
+)
+
+func TestDoctorSkipsSymlinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("windows 下不检查权限")
+	}
+	dir := filepath.Join(t.TempDir(), "keystore")
+	if err := os.Mkdir(dir, keystore.DirPerm); err != nil {
+		t.Fatal(err)
+	}
+	target := filepath.Join(t.TempDir(), "target")
+	if err := ioutil.WriteFile(target, []byte("target"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(target, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(target, filepath.Join(dir, "link")); err != nil {
+		t.Fatal(err)
+	}
+	loose := filepath.Join(dir, "loose")
+	if err := ioutil.WriteFile(loose, []byte("loose"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(loose, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	issues, err := keystore.Doctor(dir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 2 {
+		t.Fatalf("应发现 2 处问题，实际为 %v", issues)
+	}
+	for _, issue := range issues {
+		switch filepath.Base(issue.Path) {
+		case "link":
+			if !issue.Symlink || issue.Fixed {
+				t.Fatalf("符号链接应只报告不修复: %+v", issue)
+			}
+		case "loose":
+			if !issue.Fixed {
+				t.Fatalf("文件权限应被修复: %+v", issue)
+			}
+		}
+	}
+	info, err := os.Stat(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0644 {
+		t.Fatalf("不应修改符号链接目标的权限，实际为 %#o", info.Mode().Perm())
+	}
+	info, err = os.Stat(loose)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != keystore.FilePerm {
+		t.Fatalf("文件权限应修复为 %#o，实际为 %#o", keystore.FilePerm, info.Mode().Perm())
+	}
+}
@@ -0,0 +1,259 @@
+package keystore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/crypto/sha3"
+)
+
+// Web3 Secret Storage（keystore v3 JSON）参数
+const (
+	Web3Version = 3
+
+	web3Cipher  = "aes-128-ctr"
+	web3KDF     = "scrypt"
+	web3DKLen   = 32
+	web3ScryptN = 1 << 18
+	web3ScryptR = 8
+	web3ScryptP = 1
+
+	// 导入时允许的最大 KDF 参数，scrypt 内存占用为 128*n*r 字节，不超过 1 GiB
+	maxWeb3ScryptN   = 1 << 20
+	maxWeb3ScryptR   = 32
+	maxWeb3ScryptP   = 16
+	maxWeb3ScryptMem = 1 << 30
+	maxWeb3PBKDF2C   = 10000000
+	maxWeb3DKLen     = 64
+)
+
+// 错误
+var (
+	ErrWeb3MAC = errors.New("keystore v3 MAC 校验失败，口令错误或文件已被篡改")
+)
+
+// Web3Key keystore v3 JSON 中保存的密钥内容
+type Web3Key struct {
+	Address  string            // 账户地址
+	Key      []byte            // 私钥
+	Mnemonic string            // 可选，加密保存在扩展字段 x-mnemonic 中
+	Meta     map[string]string // 可选，明文保存在扩展字段 x-meta 中
+}
+
+type web3JSON struct {
+	Address  string            `json:"address"`
+	Crypto   web3Crypto        `json:"crypto"`
+	ID       string            `json:"id"`
+	Version  int               `json:"version"`
+	Mnemonic *web3Crypto       `json:"x-mnemonic,omitempty"`
+	Meta     map[string]string `json:"x-meta,omitempty"`
+}
+
+type web3Crypto struct {
+	Cipher       string                 `json:"cipher"`
+	CipherText   string                 `json:"ciphertext"`
+	CipherParams web3CipherParams       `json:"cipherparams"`
+	KDF          string                 `json:"kdf"`
+	KDFParams    map[string]interface{} `json:"kdfparams"`
+	MAC          string                 `json:"mac"`
+}
+
+type web3CipherParams struct {
+	IV string `json:"iv"`
+}
+
+// EncryptWeb3Key 按 Web3 Secret Storage v3 格式加密密钥，助记词使用同一口令单独加密
+func EncryptWeb3Key(key *Web3Key, password string) ([]byte, error) {
+	c, err := encryptWeb3(key.Key, password)
+	if err != nil {
+		return nil, err
+	}
+	id, err := NewUUID()
+	if err != nil {
+		return nil, err
+	}
+	kj := &web3JSON{
+		Address: strings.ToLower(strings.TrimPrefix(key.Address, "0x")),
+		Crypto:  *c,
+		ID:      id,
+		Version: Web3Version,
+		Meta:    key.Meta,
+	}
+	if len(key.Mnemonic) != 0 {
+		kj.Mnemonic, err = encryptWeb3([]byte(key.Mnemonic), password)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return json.MarshalIndent(kj, "", "  ")
+}
+
+// DecryptWeb3Key 解密 Web3 Secret Storage v3 格式密钥，支持 scrypt 和 pbkdf2 两种 KDF
+func DecryptWeb3Key(data []byte, password string) (*Web3Key, error) {
+	kj := &web3JSON{}
+	err := json.Unmarshal(data, kj)
+	if err != nil {
+		return nil, errors.Wrap(err, "解析 keystore v3 JSON 失败")
+	}
+	if kj.Version != Web3Version {
+		return nil, errors.Errorf("不支持的 keystore 版本 %d", kj.Version)
+	}
+	key, err := decryptWeb3(&kj.Crypto, password)
+	if err != nil {
+		return nil, err
+	}
+	wk := &Web3Key{
+		Address: kj.Address,
+		Key:     key,
+		Meta:    kj.Meta,
+	}
+	if kj.Mnemonic != nil {
+		mnemonic, err := decryptWeb3(kj.Mnemonic, password)
+		if err != nil {
+			return nil, errors.WithMessage(err, "解密助记词失败")
+		}
+		wk.Mnemonic = string(mnemonic)
+	}
+	return wk, nil
+}
+
+func encryptWeb3(plain []byte, password string) (*web3Crypto, error) {
+	salt := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+	dk, err := scrypt.Key([]byte(password), salt, web3ScryptN, web3ScryptR, web3ScryptP, web3DKLen)
+	if err != nil {
+		return nil, err
+	}
+	iv := make([]byte, aes.BlockSize)
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		return nil, err
+	}
+	ciphertext, err := aesCTR(dk[:16], iv, plain)
+	if err != nil {
+		return nil, err
+	}
+	return &web3Crypto{
+		Cipher:       web3Cipher,
+		CipherText:   hex.EncodeToString(ciphertext),
+		CipherParams: web3CipherParams{IV: hex.EncodeToString(iv)},
+		KDF:          web3KDF,
+		KDFParams: map[string]interface{}{
+			"n":     web3ScryptN,
+			"r":     web3ScryptR,
+			"p":     web3ScryptP,
+			"dklen": web3DKLen,
+			"salt":  hex.EncodeToString(salt),
+		},
+		MAC: hex.EncodeToString(web3MAC(dk, ciphertext)),
+	}, nil
+}
+
+func decryptWeb3(c *web3Crypto, password string) ([]byte, error) {
+	if c.Cipher != web3Cipher {
+		return nil, errors.Errorf("不支持的加密算法 %s", c.Cipher)
+	}
+	mac, err := hex.DecodeString(c.MAC)
+	if err != nil {
+		return nil, errors.Wrap(err, "mac 格式错误")
+	}
+	iv, err := hex.DecodeString(c.CipherParams.IV)
+	if err != nil {
+		return nil, errors.Wrap(err, "iv 格式错误")
+	}
+	if len(iv) != aes.BlockSize {
+		return nil, errors.Errorf("iv 长度应为 %d 字节，实际为 %d 字节", aes.BlockSize, len(iv))
+	}
+	ciphertext, err := hex.DecodeString(c.CipherText)
+	if err != nil {
+		return nil, errors.Wrap(err, "ciphertext 格式错误")
+	}
+	dk, err := web3DeriveKey(c, password)
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare(web3MAC(dk, ciphertext), mac) != 1 {
+		return nil, ErrWeb3MAC
+	}
+	return aesCTR(dk[:16], iv, ciphertext)
+}
+
+func web3DeriveKey(c *web3Crypto, password string) ([]byte, error) {
+	salt, err := hex.DecodeString(fmt.Sprint(c.KDFParams["salt"]))
+	if err != nil {
+		return nil, errors.Wrap(err, "salt 格式错误")
+	}
+	dkLen := kdfParam(c.KDFParams, "dklen")
+	if dkLen < 32 || dkLen > maxWeb3DKLen {
+		return nil, errors.Errorf("dklen %d 非法", dkLen)
+	}
+	switch c.KDF {
+	case "scrypt":
+		n, r, p := kdfParam(c.KDFParams, "n"), kdfParam(c.KDFParams, "r"), kdfParam(c.KDFParams, "p")
+		if n <= 1 || n > maxWeb3ScryptN || r <= 0 || r > maxWeb3ScryptR || p <= 0 || p > maxWeb3ScryptP ||
+			128*n*r > maxWeb3ScryptMem {
+			return nil, errors.Errorf("scrypt 参数非法 n=%d r=%d p=%d", n, r, p)
+		}
+		return scrypt.Key([]byte(password), salt, n, r, p, dkLen)
+	case "pbkdf2":
+		if prf := fmt.Sprint(c.KDFParams["prf"]); prf != "hmac-sha256" {
+			return nil, errors.Errorf("不支持的 pbkdf2 prf %s", prf)
+		}
+		iter := kdfParam(c.KDFParams, "c")
+		if iter <= 0 || iter > maxWeb3PBKDF2C {
+			return nil, errors.Errorf("pbkdf2 参数非法 c=%d", iter)
+		}
+		return pbkdf2.Key([]byte(password), salt, iter, dkLen, sha256.New), nil
+	}
+	return nil, errors.Errorf("不支持的 KDF %s", c.KDF)
+}
+
+func kdfParam(params map[string]interface{}, name string) int {
+	switch v := params[name].(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	}
+	return 0
+}
+
+func web3MAC(dk, ciphertext []byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	h.Write(dk[16:32])
+	h.Write(ciphertext)
+	return h.Sum(nil)
+}
+
+func aesCTR(key, iv, in []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, len(in))
+	cipher.NewCTR(block, iv).XORKeyStream(out, in)
+	return out, nil
+}
+
+// NewUUID 生成随机 UUID（版本 4）
+func NewUUID() (string, error) {
+	u := make([]byte, 16)
+	if _, err := io.ReadFull(rand.Reader, u); err != nil {
+		return "", err
+	}
+	u[6] = (u[6] & 0x0f) | 0x40
+	u[8] = (u[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:]), nil
+}
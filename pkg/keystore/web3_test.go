@@ -0,0 +1,67 @@
+package keystore_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"bewallet/pkg/keystore"
+)
+
+func TestWeb3KeyRoundTrip(t *testing.T) {
+	key := &keystore.Web3Key{
+		Address:  "0xabc",
+		Key:      bytes.Repeat([]byte{1}, 32),
+		Mnemonic: "abandon ability",
+		Meta:     map[string]string{"curve": "P-256"},
+	}
+	data, err := keystore.EncryptWeb3Key(key, "web3-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := keystore.DecryptWeb3Key(data, "web3-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.Key, key.Key) || got.Mnemonic != key.Mnemonic || got.Address != "abc" {
+		t.Fatalf("解密结果不正确: %+v", got)
+	}
+	if _, err := keystore.DecryptWeb3Key(data, "wrong"); err != keystore.ErrWeb3MAC {
+		t.Fatalf("口令错误时应返回 ErrWeb3MAC，实际为 %v", err)
+	}
+}
+
+func TestWeb3KeyRejectsInvalidParams(t *testing.T) {
+	data, err := keystore.EncryptWeb3Key(&keystore.Web3Key{Key: bytes.Repeat([]byte{1}, 32)}, "web3-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := map[string]func(c map[string]interface{}){
+		"短 iv": func(c map[string]interface{}) {
+			c["cipherparams"] = map[string]interface{}{"iv": "00"}
+		},
+		"过大的 r": func(c map[string]interface{}) {
+			c["kdfparams"].(map[string]interface{})["r"] = 1 << 20
+		},
+		"过大的 p": func(c map[string]interface{}) {
+			c["kdfparams"].(map[string]interface{})["p"] = 1 << 20
+		},
+		"过大的 dklen": func(c map[string]interface{}) {
+			c["kdfparams"].(map[string]interface{})["dklen"] = 1 << 20
+		},
+	}
+	for name, modify := range cases {
+		kj := map[string]interface{}{}
+		if err := json.Unmarshal(data, &kj); err != nil {
+			t.Fatal(err)
+		}
+		modify(kj["crypto"].(map[string]interface{}))
+		bad, err := json.Marshal(kj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := keystore.DecryptWeb3Key(bad, "web3-password"); err == nil {
+			t.Fatalf("%s: 应返回错误", name)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package nft
 
 import (
 	"bewallet/pkg/fab/sdk"
+	"bewallet/pkg/wallet"
 	"context"
 	"fmt"
 
@@ -17,24 +18,76 @@ type Client struct {
 	ordererClis []*sdk.OrdererClient
 }
 
-// NewClient ...
-func NewClient(opts ...Option) *Client {
+// NewClient 创建合约客户端，须指定签名钱包、合约及至少一个 peer 节点，Invoke 还需要 orderer 节点
+func NewClient(opts ...Option) (*Client, error) {
 	opt := &option{}
 	for _, o := range opts {
 		o(opt)
 	}
-	// TODO: 参数检查
 	if opt.signer == nil {
-		return nil //TODO: return error
+		return nil, errors.New("缺少签名钱包")
+	}
+	if len(opt.channel) == 0 || len(opt.chaincode) == 0 {
+		return nil, errors.New("缺少通道或合约名称")
+	}
+	if len(opt.peers) == 0 {
+		return nil, errors.New("缺少 peer 节点")
 	}
 	c := &Client{
 		opt: opt,
 	}
-	err := c.initClients() //TODO:
+	err := c.initClients()
 	if err != nil {
-		return nil
+		return nil, err
+	}
+	return c, nil
+}
+
+// NewClientFromManager 使用 Manager 中账户 account（名称或地址）在网络 network 中的身份及节点创建合约客户端，
+// opts 在账户信息之后生效，可用于覆盖合约参数或追加节点
+func NewClientFromManager(m *wallet.Manager, account, network, channel, chaincode string, opts ...Option) (*Client, error) {
+	nets := m.GetNetworks(account)
+	if nets == nil {
+		return nil, errors.Errorf("账户 %s 不存在", account)
+	}
+	fabnet, ok := nets[network]
+	if !ok {
+		return nil, errors.Errorf("账户 %s 没有网络 %s", account, network)
+	}
+	if len(fabnet.SignCert) == 0 || len(fabnet.OrgMSP) == 0 {
+		return nil, errors.Errorf("账户 %s 在网络 %s 中没有身份证书", account, network)
+	}
+	if len(fabnet.Peers) == 0 {
+		return nil, errors.Errorf("网络 %s 没有 peer 节点", network)
+	}
+	signer := m.GetSigner(account, network)
+	if signer == nil {
+		return nil, errors.Errorf("账户 %s 没有网络 %s 的签名身份", account, network)
+	}
+	list := []Option{
+		WithSigner(signer),
+		WithContract(channel, chaincode, "", ""),
+	}
+	for _, p := range fabnet.Peers {
+		list = append(list, WithPeer(walletNode(p)))
+	}
+	for _, o := range fabnet.Orderers {
+		list = append(list, WithOrderer(walletNode(o)))
+	}
+	c, err := NewClient(append(list, opts...)...)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "账户 %s 网络 %s", account, network)
+	}
+	return c, nil
+}
+
+// walletNode 钱包网络节点转换为合约客户端节点
+func walletNode(n *wallet.Node) Node {
+	return Node{
+		URL:          n.Address,
+		TLSCert:      n.TLSCA,
+		OverrideName: n.ServerOverride,
 	}
-	return c
 }
 
 func (c *Client) initClients() error {
@@ -0,0 +1,65 @@
+package nft
+
+import (
+	"strings"
+	"testing"
+
+	"bewallet/pkg/keystore"
+	"bewallet/pkg/wallet"
+)
+
+func TestNewClientFromManager(t *testing.T) {
+	ks := keystore.NewMemKeyStore()
+	caWallet, err := wallet.CreateWallet(ks, "ca")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, err := caWallet.NewDevCA("dev", "Org1MSP", &wallet.DevCAOpts{Org: "org1.example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice, err := wallet.CreateWallet(ks, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = ca.Enroll(alice, nil); err != nil {
+		t.Fatal(err)
+	}
+	m, err := wallet.NewManager(ks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = m.AddNetwork("alice", wallet.Network{Name: "bare"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		account, network string
+		want             string
+	}{
+		{"nobody", "dev", "不存在"},
+		{"alice", "missing", "没有网络"},
+		{"alice", "bare", "没有身份证书"},
+		{"alice", "dev", "没有 peer 节点"},
+	} {
+		_, err := NewClientFromManager(m, c.account, c.network, "mychannel", "nft")
+		if err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Fatalf("账户 %s 网络 %s 应返回包含 %q 的错误，实际为 %v", c.account, c.network, c.want, err)
+		}
+	}
+
+	network := wallet.Network{
+		Name:  "dev",
+		Peers: []*wallet.Node{{Address: "127.0.0.1:7051"}},
+	}
+	if err = m.UpdateNetwork("alice", network); err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewClientFromManager(m, alice.Address(), "dev", "mychannel", "nft")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.peerClis) != 1 || c.opt.channel != "mychannel" || c.opt.chaincode != "nft" {
+		t.Fatalf("客户端参数不正确: %+v", c.opt)
+	}
+}
@@ -26,9 +26,9 @@ func WithPeer(peer Node) Option {
 }
 
 // WithOrderer orderer 节点参数
-func WithOrderer(peer Node) Option {
+func WithOrderer(orderer Node) Option {
 	return func(opt *option) {
-		opt.peers = append(opt.peers, peer)
+		opt.orderers = append(opt.orderers, orderer)
 	}
 }
 
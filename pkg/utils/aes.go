@@ -14,7 +14,9 @@ const (
 	AESSize = 16
 )
 
-// AESEncrypt 加密
+// AESEncrypt 加密（早期格式，无盐、无密钥派生且不校验完整性）
+//
+// Deprecated: 使用 Seal
 func AESEncrypt(plaintext []byte, key []byte) ([]byte, error) {
 	block, err := aes.NewCipher(preKey(key))
 	if err != nil {
@@ -31,7 +33,7 @@ func AESEncrypt(plaintext []byte, key []byte) ([]byte, error) {
 	return ciphertext, nil
 }
 
-// AESDecrypt 解密
+// AESDecrypt 解密 AESEncrypt 加密的早期格式数据
 func AESDecrypt(ciphertext []byte, key []byte) ([]byte, error) {
 	block, err := aes.NewCipher(preKey(key))
 	if err != nil {
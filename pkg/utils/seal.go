@@ -0,0 +1,118 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"io"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// 口令加密格式
+//
+//	magic(4) | version(1) | logN(1) | r(1) | p(1) | salt(16) | nonce(12) | AES-256-GCM 密文及认证标签
+//
+// 密钥由 scrypt(口令, salt, 2^logN, r, p) 派生，每次加密使用新的随机 salt 和 nonce，
+// 头部作为 GCM 附加数据参与认证，口令错误或数据被篡改都会导致解密失败。
+const (
+	// SealVersion 当前加密格式版本
+	SealVersion = 1
+
+	sealMagic     = "BWKS"
+	sealSaltSize  = 16
+	sealHeaderLen = len(sealMagic) + 4 + sealSaltSize
+	sealKeySize   = 32
+
+	scryptLogN = 15
+	scryptR    = 8
+	scryptP    = 1
+
+	// 解密时允许的最大 scrypt 参数，防止被篡改的头部耗尽资源
+	maxScryptLogN = 20
+	maxScryptRP   = 64
+)
+
+// 错误
+var (
+	ErrSealAuth    = errors.New("解密失败，口令错误或数据已被篡改")
+	ErrSealFormat  = errors.New("加密数据格式错误")
+	ErrSealVersion = errors.New("不支持的加密格式版本")
+)
+
+// Seal 使用口令加密并认证数据
+func Seal(plaintext []byte, password []byte) ([]byte, error) {
+	header := make([]byte, sealHeaderLen)
+	copy(header, sealMagic)
+	header[4] = SealVersion
+	header[5] = scryptLogN
+	header[6] = scryptR
+	header[7] = scryptP
+	salt := header[8:]
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+
+	aead, err := sealAEAD(password, salt, scryptLogN, scryptR, scryptP)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, len(header)+len(nonce)+len(plaintext)+aead.Overhead())
+	out = append(out, header...)
+	out = append(out, nonce...)
+	return aead.Seal(out, nonce, plaintext, header), nil
+}
+
+// Open 解密由 Seal 加密的数据
+func Open(data []byte, password []byte) ([]byte, error) {
+	if !IsSealed(data) {
+		return nil, ErrSealFormat
+	}
+	header := data[:sealHeaderLen]
+	if header[4] != SealVersion {
+		return nil, ErrSealVersion
+	}
+	logN, r, p := int(header[5]), int(header[6]), int(header[7])
+	if logN == 0 || logN > maxScryptLogN || r == 0 || p == 0 || r*p > maxScryptRP {
+		return nil, ErrSealFormat
+	}
+
+	aead, err := sealAEAD(password, header[8:], logN, r, p)
+	if err != nil {
+		return nil, err
+	}
+	rest := data[sealHeaderLen:]
+	if len(rest) < aead.NonceSize()+aead.Overhead() {
+		return nil, ErrSealFormat
+	}
+	nonce, ciphertext := rest[:aead.NonceSize()], rest[aead.NonceSize():]
+	plain, err := aead.Open(nil, nonce, ciphertext, header)
+	if err != nil {
+		return nil, ErrSealAuth
+	}
+	return plain, nil
+}
+
+// IsSealed 判断数据是否为 Seal 加密格式
+func IsSealed(data []byte) bool {
+	return len(data) >= sealHeaderLen && bytes.Equal(data[:len(sealMagic)], []byte(sealMagic))
+}
+
+func sealAEAD(password, salt []byte, logN, r, p int) (cipher.AEAD, error) {
+	key, err := scrypt.Key(password, salt, 1<<uint(logN), r, p, sealKeySize)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,85 @@
+package utils
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSealRoundTrip(t *testing.T) {
+	plain := []byte("secret")
+	a, err := Seal(plain, []byte("Passw0rd!x"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Seal(plain, []byte("Passw0rd!x"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(a, b) {
+		t.Fatal("每次加密应使用新的 salt 和 nonce")
+	}
+	if !IsSealed(a) || IsSealed(plain) {
+		t.Fatal("IsSealed 判断错误")
+	}
+	got, err := Open(a, []byte("Passw0rd!x"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, plain) {
+		t.Fatalf("解密结果为 %q", got)
+	}
+	if _, err := Open(a, []byte("wrong")); err != ErrSealAuth {
+		t.Fatalf("口令错误时应返回 ErrSealAuth，实际为 %v", err)
+	}
+}
+
+func TestOpenTampered(t *testing.T) {
+	data, err := Seal([]byte("secret"), []byte("Passw0rd!x"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonceAt := sealHeaderLen
+	cases := []struct {
+		name string
+		at   int
+		want error
+	}{
+		{"magic", 0, ErrSealFormat},
+		{"version", 4, ErrSealVersion},
+		{"scrypt", 5, ErrSealFormat},
+		{"salt", 8, ErrSealAuth},
+		{"nonce", nonceAt, ErrSealAuth},
+		{"ciphertext", nonceAt + 12, ErrSealAuth},
+		{"tag", len(data) - 1, ErrSealAuth},
+	}
+	for _, c := range cases {
+		tampered := append([]byte(nil), data...)
+		tampered[c.at] ^= 0xff
+		if _, err := Open(tampered, []byte("Passw0rd!x")); err != c.want {
+			t.Fatalf("篡改 %s 时应返回 %v，实际为 %v", c.name, c.want, err)
+		}
+	}
+	if _, err := Open(data[:nonceAt+12], []byte("Passw0rd!x")); err != ErrSealFormat {
+		t.Fatalf("数据被截断时应返回 ErrSealFormat，实际为 %v", err)
+	}
+}
+
+func TestAESDecryptLegacy(t *testing.T) {
+	data, err := AESEncrypt([]byte("secret"), []byte("Passw0rd!x"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if IsSealed(data) {
+		t.Fatal("早期格式不应被识别为 Seal 格式")
+	}
+	plain, err := AESDecrypt(data, []byte("Passw0rd!x"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(plain) != "secret" {
+		t.Fatalf("解密结果为 %q", plain)
+	}
+	if _, err := AESDecrypt(data[:8], []byte("Passw0rd!x")); err == nil {
+		t.Fatal("数据过短时应返回错误")
+	}
+}
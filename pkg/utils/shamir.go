@@ -0,0 +1,125 @@
+package utils
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+)
+
+// Shamir 秘密共享，在 GF(2^8)（AES 多项式 x^8+x^4+x^3+x+1）上对秘密逐字节构造
+// threshold-1 次随机多项式，第 i 份份额为多项式在 x=i 处的取值。
+// 份额格式为 x(1) | y(len(secret))，任意 threshold 份份额经拉格朗日插值可还原秘密，
+// 少于 threshold 份时不泄露秘密的任何信息。
+
+// 错误
+var (
+	ErrShamirParams = errors.New("份额参数非法，要求 2 <= threshold <= shares <= 255")
+	ErrShamirShares = errors.New("份额格式错误或不一致")
+)
+
+var gfExp, gfLog [256]byte
+
+func init() {
+	x := byte(1)
+	for i := 0; i < 255; i++ {
+		gfExp[i] = x
+		gfLog[x] = byte(i)
+		x = gfMulNoTable(x, 3)
+	}
+	gfExp[255] = gfExp[0]
+}
+
+// gfMulNoTable 不查表的乘法，仅用于以生成元 3 构造指数表和对数表
+func gfMulNoTable(a, b byte) byte {
+	var p byte
+	for b != 0 {
+		if b&1 != 0 {
+			p ^= a
+		}
+		hi := a & 0x80
+		a <<= 1
+		if hi != 0 {
+			a ^= 0x1b
+		}
+		b >>= 1
+	}
+	return p
+}
+
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExp[(int(gfLog[a])+int(gfLog[b]))%255]
+}
+
+func gfDiv(a, b byte) byte {
+	if a == 0 {
+		return 0
+	}
+	return gfExp[(int(gfLog[a])+255-int(gfLog[b]))%255]
+}
+
+// ShamirSplit 将 secret 拆分为 shares 份份额，任意 threshold 份可还原
+func ShamirSplit(secret []byte, shares, threshold int) ([][]byte, error) {
+	if threshold < 2 || threshold > shares || shares > 255 {
+		return nil, ErrShamirParams
+	}
+	if len(secret) == 0 {
+		return nil, errors.New("秘密不能为空")
+	}
+	coef := make([]byte, threshold)
+	out := make([][]byte, shares)
+	for i := range out {
+		out[i] = make([]byte, len(secret)+1)
+		out[i][0] = byte(i + 1)
+	}
+	for j, s := range secret {
+		coef[0] = s
+		if _, err := io.ReadFull(rand.Reader, coef[1:]); err != nil {
+			return nil, err
+		}
+		for i := range out {
+			x := out[i][0]
+			var y byte
+			for k := threshold - 1; k >= 0; k-- {
+				y = gfMul(y, x) ^ coef[k]
+			}
+			out[i][j+1] = y
+		}
+	}
+	for i := range coef {
+		coef[i] = 0
+	}
+	return out, nil
+}
+
+// ShamirCombine 由份额还原秘密，份额数量须不少于拆分时的 threshold，否则得到的结果是错误的
+func ShamirCombine(shares [][]byte) ([]byte, error) {
+	if len(shares) < 2 {
+		return nil, ErrShamirShares
+	}
+	size := len(shares[0])
+	seen := make(map[byte]bool, len(shares))
+	for _, s := range shares {
+		if len(s) != size || size < 2 || s[0] == 0 || seen[s[0]] {
+			return nil, ErrShamirShares
+		}
+		seen[s[0]] = true
+	}
+	secret := make([]byte, size-1)
+	for i, si := range shares {
+		// 拉格朗日基函数在 x=0 处的取值：prod(xj / (xj - xi))，GF(2^8) 中减法即异或
+		basis := byte(1)
+		for j, sj := range shares {
+			if i == j {
+				continue
+			}
+			basis = gfMul(basis, gfDiv(sj[0], sj[0]^si[0]))
+		}
+		for k := range secret {
+			secret[k] ^= gfMul(si[k+1], basis)
+		}
+	}
+	return secret, nil
+}
@@ -0,0 +1,84 @@
+package wallet
+
+import (
+	"encoding/json"
+	"time"
+
+	"bewallet/pkg/keystore"
+)
+
+// accountMeta 保存在密钥存储中的账户元数据
+type accountMeta struct {
+	ID      string
+	Addr    string
+	Created time.Time
+	Labels  map[string]string `json:",omitempty"`
+}
+
+// readMeta 读取账户元数据，元数据不存在或与账户地址不一致时返回 nil，不写入密钥存储
+func (w *Wallet) readMeta() (*accountMeta, error) {
+	data, err := w.KeyStore.Load(&keystore.MetaLoadOpt{Name: w.name})
+	if err != nil || len(data) == 0 {
+		return nil, err
+	}
+	meta := &accountMeta{}
+	err = json.Unmarshal(data, meta)
+	if err != nil {
+		return nil, err
+	}
+	if meta.Addr != w.addr {
+		return nil, nil
+	}
+	return meta, nil
+}
+
+// newMeta 生成并保存新的账户元数据
+func (w *Wallet) newMeta() (*accountMeta, error) {
+	id, err := keystore.NewUUID()
+	if err != nil {
+		return nil, err
+	}
+	meta := &accountMeta{
+		ID:      id,
+		Addr:    w.addr,
+		Created: time.Now().UTC(),
+	}
+	err = w.saveMeta(meta)
+	if err != nil {
+		return nil, err
+	}
+	return meta, nil
+}
+
+func (w *Wallet) saveMeta(meta *accountMeta) error {
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	return w.KeyStore.Store(&keystore.MetaStoreOpt{Name: w.name, Content: data})
+}
+
+// account 由钱包及元数据生成账户信息，meta 为 nil 时 ID、Created 及 Labels 为空
+func (w *Wallet) account(meta *accountMeta) *Account {
+	acc := &Account{
+		Name:       w.name,
+		Addr:       w.addr,
+		Version:    w.version,
+		Path:       w.path,
+		Root:       w.root,
+		Language:   w.language,
+		Passphrase: w.passphrase,
+	}
+	if meta == nil {
+		return acc
+	}
+	acc.ID = meta.ID
+	acc.Created = meta.Created
+	if len(meta.Labels) != 0 {
+		acc.Labels = make(map[string]string, len(meta.Labels))
+		for k, v := range meta.Labels {
+			acc.Labels[k] = v
+		}
+	}
+	return acc
+}
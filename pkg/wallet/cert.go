@@ -0,0 +1,71 @@
+package wallet
+
+import (
+	"crypto/ecdsa"
+	"crypto/x509"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// 错误
+var (
+	ErrNoSignCert       = errors.New("没有身份证书")
+	ErrCertKeyMismatch  = errors.New("证书公钥与钱包私钥不匹配")
+	ErrCertExpired      = errors.New("证书已过期")
+	ErrCertNotYetValid  = errors.New("证书尚未生效")
+	ErrCertMSPMismatch  = errors.New("证书不是由 MSP 根证书签发")
+	ErrCertOrgMSPChange = errors.New("证书所属 MSP 与已有身份不一致")
+)
+
+// Certificate 解析身份证书
+func (fm FabMSP) Certificate() (*x509.Certificate, error) {
+	if len(fm.SignCert) == 0 {
+		return nil, ErrNoSignCert
+	}
+	return parseCertPEM([]byte(fm.SignCert))
+}
+
+// NotAfter 身份证书到期时间
+func (fm FabMSP) NotAfter() (time.Time, error) {
+	cert, err := fm.Certificate()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return cert.NotAfter, nil
+}
+
+// checkCert 校验证书有效期，以及已配置 MSP 根证书时证书是否由其签发
+func (fm FabMSP) checkCert(cert *x509.Certificate, now time.Time) error {
+	if now.Before(cert.NotBefore) {
+		return errors.WithMessagef(ErrCertNotYetValid, "生效时间 %s", cert.NotBefore.Format(time.RFC3339))
+	}
+	if now.After(cert.NotAfter) {
+		return errors.WithMessagef(ErrCertExpired, "到期时间 %s", cert.NotAfter.Format(time.RFC3339))
+	}
+	if len(fm.CACerts) == 0 {
+		return nil
+	}
+	opts := x509.VerifyOptions{
+		Roots:         x509.NewCertPool(),
+		Intermediates: x509.NewCertPool(),
+		CurrentTime:   now,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}
+	for _, c := range fm.CACerts {
+		opts.Roots.AppendCertsFromPEM([]byte(c))
+	}
+	for _, c := range fm.IntermediateCerts {
+		opts.Intermediates.AppendCertsFromPEM([]byte(c))
+	}
+	if _, err := cert.Verify(opts); err != nil {
+		return errors.WithMessage(ErrCertMSPMismatch, err.Error())
+	}
+	return nil
+}
+
+// certMatchesKey 证书公钥是否与私钥对应
+func certMatchesKey(cert *x509.Certificate, pub *ecdsa.PublicKey) bool {
+	cpub, ok := cert.PublicKey.(*ecdsa.PublicKey)
+	return ok && cpub.Equal(pub)
+}
@@ -0,0 +1,107 @@
+package wallet
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/json"
+	"encoding/pem"
+	"net"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultCNTemplate 证书请求默认通用名模板
+const DefaultCNTemplate = "{address}@{org}"
+
+// 默认身份类型
+const defaultOU = "client"
+
+// fabricAttrOID Fabric CA 证书属性扩展
+var fabricAttrOID = asn1.ObjectIdentifier{1, 2, 3, 4, 5, 6, 7, 8, 1}
+
+// CSROpts 证书请求参数，为 nil 或字段为空时使用默认值
+type CSROpts struct {
+	OUs []string // 组织单位，即身份类型，如 client、peer、admin、orderer，默认为 client
+	Org string   // 组织，默认为网络身份中的组织
+	// 通用名模板，支持 {address}、{name}、{org}、{network} 占位符，默认为 DefaultCNTemplate
+	CNTemplate string
+
+	// 地址信息，任一字段非空时使用 subjectTemplateAdditional 生成，未指定的国家、省份、城市取默认值
+	Country       string
+	Province      string
+	Locality      string
+	StreetAddress string
+	PostalCode    string
+
+	DNSNames       []string
+	IPAddresses    []net.IP
+	EmailAddresses []string
+
+	// Fabric 证书属性，以 Fabric CA 相同的格式写入扩展 1.2.3.4.5.6.7.8.1，签发时由 CA 决定是否保留
+	Attrs map[string]string
+}
+
+// CertRequestPEM 生成 PEM 格式证书请求
+func (fw FabWallet) CertRequestPEM(opts *CSROpts) ([]byte, error) {
+	der, err := fw.CertRequest(opts)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der}), nil
+}
+
+func (fw FabWallet) csrTemplate(opts *CSROpts) (*x509.CertificateRequest, error) {
+	if opts == nil {
+		opts = &CSROpts{}
+	}
+	org := opts.Org
+	if len(org) == 0 {
+		org = fw.Org
+	}
+	var subject pkix.Name
+	if len(opts.Country+opts.Province+opts.Locality+opts.StreetAddress+opts.PostalCode) != 0 {
+		subject = subjectTemplateAdditional(opts.Country, opts.Province, opts.Locality, "", opts.StreetAddress, opts.PostalCode)
+	}
+	subject.OrganizationalUnit = opts.OUs
+	if len(subject.OrganizationalUnit) == 0 {
+		subject.OrganizationalUnit = []string{defaultOU}
+	}
+	if len(org) != 0 {
+		subject.Organization = []string{org}
+	}
+	cn := opts.CNTemplate
+	if len(cn) == 0 {
+		cn = DefaultCNTemplate
+	}
+	subject.CommonName = strings.NewReplacer(
+		"{address}", fw.Address(),
+		"{name}", fw.name,
+		"{org}", org,
+		"{network}", fw.FabMSP.Network,
+	).Replace(cn)
+	if len(subject.CommonName) == 0 || len(subject.CommonName) > 64 {
+		return nil, errors.Errorf("通用名 %q 长度应为 1 到 64", subject.CommonName)
+	}
+
+	template := &x509.CertificateRequest{
+		Subject:        subject,
+		DNSNames:       opts.DNSNames,
+		IPAddresses:    opts.IPAddresses,
+		EmailAddresses: opts.EmailAddresses,
+	}
+	if len(opts.Attrs) != 0 {
+		value, err := json.Marshal(struct {
+			Attrs map[string]string `json:"attrs"`
+		}{opts.Attrs})
+		if err != nil {
+			return nil, err
+		}
+		template.ExtraExtensions = append(template.ExtraExtensions, pkix.Extension{
+			Id:    fabricAttrOID,
+			Value: value,
+		})
+	}
+	return template, nil
+}
@@ -0,0 +1,118 @@
+package wallet
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"net"
+	"strings"
+	"testing"
+
+	"bewallet/pkg/keystore"
+)
+
+func testFabWallet(t *testing.T) *FabWallet {
+	w, err := CreateWallet(keystore.NewMemKeyStore(), "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fw := &FabWallet{Wallet: *w}
+	fw.FabMSP.Network = "net"
+	fw.Org = "org1.example.com"
+	return fw
+}
+
+func parseCSR(t *testing.T, data []byte) *x509.CertificateRequest {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE REQUEST" {
+		t.Fatal("证书请求不是 PEM 格式")
+	}
+	req, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := req.CheckSignature(); err != nil {
+		t.Fatal(err)
+	}
+	return req
+}
+
+func TestCertRequestDefaults(t *testing.T) {
+	fw := testFabWallet(t)
+	data, err := fw.CertRequestPEM(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := parseCSR(t, data)
+	if req.Subject.CommonName != fw.Address()+"@org1.example.com" {
+		t.Fatalf("通用名为 %s", req.Subject.CommonName)
+	}
+	if len(req.Subject.OrganizationalUnit) != 1 || req.Subject.OrganizationalUnit[0] != "client" {
+		t.Fatalf("组织单位为 %v", req.Subject.OrganizationalUnit)
+	}
+	if len(req.Subject.Organization) != 1 || req.Subject.Organization[0] != "org1.example.com" {
+		t.Fatalf("组织为 %v", req.Subject.Organization)
+	}
+}
+
+func TestCertRequestOpts(t *testing.T) {
+	fw := testFabWallet(t)
+	data, err := fw.CertRequestPEM(&CSROpts{
+		OUs:            []string{"peer", "admin"},
+		Org:            "org2.example.com",
+		CNTemplate:     "{name}.{network}@{org}",
+		Country:        "CN",
+		Province:       "Zhejiang",
+		Locality:       "Hangzhou",
+		DNSNames:       []string{"peer0.org2.example.com"},
+		IPAddresses:    []net.IP{net.ParseIP("10.0.0.1")},
+		EmailAddresses: []string{"alice@example.com"},
+		Attrs:          map[string]string{"role": "auditor"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := parseCSR(t, data)
+	s := req.Subject
+	if s.CommonName != "alice.net@org2.example.com" {
+		t.Fatalf("通用名为 %s", s.CommonName)
+	}
+	if strings.Join(s.OrganizationalUnit, ",") != "peer,admin" || strings.Join(s.Organization, ",") != "org2.example.com" {
+		t.Fatalf("主题为 %+v", s)
+	}
+	if strings.Join(s.Country, ",") != "CN" || strings.Join(s.Province, ",") != "Zhejiang" || strings.Join(s.Locality, ",") != "Hangzhou" {
+		t.Fatalf("地址信息为 %+v", s)
+	}
+	if len(req.DNSNames) != 1 || req.DNSNames[0] != "peer0.org2.example.com" ||
+		len(req.IPAddresses) != 1 || !req.IPAddresses[0].Equal(net.ParseIP("10.0.0.1")) ||
+		len(req.EmailAddresses) != 1 || req.EmailAddresses[0] != "alice@example.com" {
+		t.Fatalf("SAN 为 %v %v %v", req.DNSNames, req.IPAddresses, req.EmailAddresses)
+	}
+	found := false
+	for _, ext := range req.Extensions {
+		if ext.Id.Equal(fabricAttrOID) {
+			found = true
+			if string(ext.Value) != `{"attrs":{"role":"auditor"}}` {
+				t.Fatalf("属性扩展为 %s", ext.Value)
+			}
+		}
+	}
+	if !found {
+		t.Fatal("缺少属性扩展")
+	}
+}
+
+func TestCertRequestCNLength(t *testing.T) {
+	fw := testFabWallet(t)
+	if _, err := fw.CertRequestPEM(&CSROpts{CNTemplate: strings.Repeat("a", 64)}); err != nil {
+		t.Fatal("64 个字符的通用名应有效:", err)
+	}
+	for _, cn := range []string{strings.Repeat("a", 65), "{address}@{org}.example.org"} {
+		if _, err := fw.CertRequestPEM(&CSROpts{CNTemplate: cn}); err == nil {
+			t.Fatalf("通用名模板 %s 超过 64 个字符时应返回错误", cn)
+		}
+	}
+	fw.Org = ""
+	if _, err := fw.CertRequestPEM(&CSROpts{CNTemplate: "{org}"}); err == nil {
+		t.Fatal("通用名为空时应返回错误")
+	}
+}
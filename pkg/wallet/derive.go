@@ -0,0 +1,172 @@
+package wallet
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// 密钥派生版本，记录在 Secret.Version 中
+const (
+	// KeyVersionSLIP10 按 SLIP-0010（NIST P-256）由 BIP-39 种子逐级派生：
+	// 主密钥 I = HMAC-SHA512("Nist256p1 seed", seed)，子密钥 I = HMAC-SHA512(chainCode, data)，
+	// 取 I 的前 32 字节为私钥标量，若为 0 或不小于 N 则按规范重新计算（拒绝采样）
+	KeyVersionSLIP10 = "slip10"
+	// KeyVersionLegacy 早期版本：种子前 40 字节对 N-1 取模后加 1，与 go1.19 及以前的 ecdsa.GenerateKey 一致
+	KeyVersionLegacy = "legacy"
+	// KeyVersionLegacyGo120 早期版本：种子按 32 字节拒绝采样，与 go1.20 及以后的 ecdsa.GenerateKey 一致
+	KeyVersionLegacyGo120 = "legacy-go1.20"
+	// KeyVersionLegacyGo120Shift 同 KeyVersionLegacyGo120，但 GenerateKey 先丢弃了种子的第一个字节
+	KeyVersionLegacyGo120Shift = "legacy-go1.20-shift"
+	// KeyVersionUnknown 早期版本，未能识别派生方式，无法由助记词重新生成当前私钥
+	KeyVersionUnknown = "unknown"
+)
+
+// 派生路径相关常量
+const (
+	// HardenedOffset 强化派生索引起始值
+	HardenedOffset uint32 = 0x80000000
+	// CoinType BIP-44 币种编号，地址格式与以太坊一致
+	CoinType uint32 = 60
+)
+
+var (
+	// DefaultPath 默认派生路径（第 0 个账户）
+	DefaultPath = AccountPath(0)
+
+	// SLIP-0010 中 NIST P-256 主密钥的 HMAC 密钥
+	curveSeedKey = []byte("Nist256p1 seed")
+)
+
+// AccountPath 返回 m/44'/60'/0'/0/index 形式的账户派生路径
+func AccountPath(index uint32) string {
+	return fmt.Sprintf("m/44'/%d'/0'/0/%d", CoinType, index)
+}
+
+// ParsePath 解析 m/44'/60'/0'/0/0 形式的派生路径，强化索引可以用 ' 或 h 标记
+func ParsePath(path string) ([]uint32, error) {
+	parts := strings.Split(strings.TrimSpace(path), "/")
+	if len(parts) == 0 || parts[0] != "m" {
+		return nil, errors.Errorf("派生路径 %s 必须以 m 开头", path)
+	}
+	indexes := make([]uint32, 0, len(parts)-1)
+	for _, p := range parts[1:] {
+		hardened := false
+		if strings.HasSuffix(p, "'") || strings.HasSuffix(p, "h") || strings.HasSuffix(p, "H") {
+			hardened = true
+			p = p[:len(p)-1]
+		}
+		i, err := strconv.ParseUint(p, 10, 32)
+		if err != nil || uint32(i) >= HardenedOffset {
+			return nil, errors.Errorf("派生路径 %s 中的索引 %s 非法", path, p)
+		}
+		index := uint32(i)
+		if hardened {
+			index += HardenedOffset
+		}
+		indexes = append(indexes, index)
+	}
+	return indexes, nil
+}
+
+// pathLess 按索引逐级比较两个派生路径
+func pathLess(a, b string) bool {
+	ia, _ := ParsePath(a)
+	ib, _ := ParsePath(b)
+	for i := 0; i < len(ia) && i < len(ib); i++ {
+		if ia[i] != ib[i] {
+			return ia[i] < ib[i]
+		}
+	}
+	return len(ia) < len(ib)
+}
+
+// extendedKey SLIP-0010 扩展私钥
+type extendedKey struct {
+	key       *big.Int
+	chainCode []byte
+}
+
+// newMasterKey 按 SLIP-0010 由种子生成主扩展私钥
+func newMasterKey(seed []byte) *extendedKey {
+	n := Curve.Params().N
+	data := seed
+	for {
+		i := hmacSHA512(curveSeedKey, data)
+		k := new(big.Int).SetBytes(i[:32])
+		if k.Sign() != 0 && k.Cmp(n) < 0 {
+			return &extendedKey{key: k, chainCode: i[32:]}
+		}
+		data = i
+	}
+}
+
+// child 按 SLIP-0010 派生子扩展私钥，index >= HardenedOffset 时为强化派生
+func (k *extendedKey) child(index uint32) *extendedKey {
+	n := Curve.Params().N
+	var data []byte
+	if index >= HardenedOffset {
+		data = append([]byte{0}, k.keyBytes()...)
+	} else {
+		x, y := Curve.ScalarBaseMult(k.keyBytes())
+		data = elliptic.MarshalCompressed(Curve, x, y)
+	}
+	data = append(data, ser32(index)...)
+	for {
+		i := hmacSHA512(k.chainCode, data)
+		il := new(big.Int).SetBytes(i[:32])
+		if il.Cmp(n) < 0 {
+			ki := il.Add(il, k.key)
+			ki.Mod(ki, n)
+			if ki.Sign() != 0 {
+				return &extendedKey{key: ki, chainCode: i[32:]}
+			}
+		}
+		data = append([]byte{1}, i[32:]...)
+		data = append(data, ser32(index)...)
+	}
+}
+
+func (k *extendedKey) derive(indexes []uint32) *extendedKey {
+	key := k
+	for _, i := range indexes {
+		key = key.child(i)
+	}
+	return key
+}
+
+func (k *extendedKey) keyBytes() []byte {
+	buf := make([]byte, 32)
+	return k.key.FillBytes(buf)
+}
+
+func (k *extendedKey) privateKey() *ecdsa.PrivateKey {
+	return scalarToPrivateKey(k.key)
+}
+
+func scalarToPrivateKey(k *big.Int) *ecdsa.PrivateKey {
+	pri := &ecdsa.PrivateKey{D: new(big.Int).Set(k)}
+	pri.PublicKey.Curve = Curve
+	pri.PublicKey.X, pri.PublicKey.Y = Curve.ScalarBaseMult(k.FillBytes(make([]byte, 32)))
+	return pri
+}
+
+func hmacSHA512(key, data []byte) []byte {
+	mac := hmac.New(sha512.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+func ser32(i uint32) []byte {
+	buf := make([]byte, 4)
+	binary.BigEndian.PutUint32(buf, i)
+	return buf
+}
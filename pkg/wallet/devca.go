@@ -0,0 +1,237 @@
+package wallet
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"bewallet/pkg/fabca"
+	"bewallet/pkg/keystore"
+)
+
+// 开发用 CA 签发的证书用途
+const (
+	CertUsageClient = "client" // 身份证书
+	CertUsageTLS    = "tls"    // TLS 证书，可同时用于服务端和客户端
+)
+
+// DevCAOpts 开发用 CA 根证书参数
+type DevCAOpts struct {
+	Org           string // 组织，必填
+	CommonName    string // 默认为 ca.<Org>
+	Country       string
+	Province      string
+	Locality      string
+	OrgUnit       string
+	StreetAddress string
+	PostalCode    string
+	Expiry        time.Duration // 默认为 10 年
+}
+
+// IssueOpts 签发证书参数
+type IssueOpts struct {
+	Usage          string   // CertUsageClient 或 CertUsageTLS，默认为 CertUsageClient
+	OUs            []string // 覆盖证书请求中的 OU，如 client、peer、admin、orderer
+	DNSNames       []string // 追加的 SAN
+	IPAddresses    []net.IP
+	EmailAddresses []string
+	Expiry         time.Duration // 默认为 10 年，不超过根证书有效期
+}
+
+// DevCA 以钱包私钥作为根私钥的轻量 CA，仅用于本地开发测试网络。
+// 根证书作为 CA 账户的一个网络身份保存，同时作为 MSP 根证书和 TLS 根证书
+type DevCA struct {
+	fw   *FabWallet
+	cert *x509.Certificate
+}
+
+// NewDevCA 生成自签名根证书，并以 network 为名保存到钱包账户网络信息中
+func (w *Wallet) NewDevCA(network, mspID string, opts *DevCAOpts) (*DevCA, error) {
+	if len(network) == 0 || len(mspID) == 0 || opts == nil || len(opts.Org) == 0 {
+		return nil, errors.New("缺少网络名称、MSP ID 或组织")
+	}
+	template := x509Template()
+	template.Subject = subjectTemplateAdditional(opts.Country, opts.Province, opts.Locality, opts.OrgUnit, opts.StreetAddress, opts.PostalCode)
+	template.Subject.Organization = []string{opts.Org}
+	template.Subject.CommonName = opts.CommonName
+	if len(template.Subject.CommonName) == 0 {
+		template.Subject.CommonName = "ca." + opts.Org
+	}
+	if opts.Expiry > 0 {
+		template.NotAfter = template.NotBefore.Add(opts.Expiry)
+	}
+	template.IsCA = true
+	template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature
+	template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth}
+	template.SubjectKeyId = ski(w)
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &w.private.PublicKey, w.private)
+	if err != nil {
+		return nil, errors.Wrap(err, "生成根证书失败")
+	}
+	certPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+
+	nets, err := LoadFabNet(w.KeyStore, w.name)
+	if err != nil && !os.IsNotExist(errors.Cause(err)) {
+		return nil, err
+	}
+	if nets == nil {
+		nets = make(map[string]*FabNet)
+	}
+	fabnet := &FabNet{
+		FabMSP: FabMSP{
+			Network:    network,
+			OrgMSP:     mspID,
+			Org:        opts.Org,
+			SignCert:   certPEM,
+			CACerts:    []string{certPEM},
+			TLSCACerts: []string{certPEM},
+		},
+		Network: Network{Name: network},
+	}
+	if old, ok := nets[network]; ok {
+		fabnet.Network = old.Network
+	}
+	nets[network] = fabnet
+	err = SaveFabNet(w.KeyStore, w.name, nets)
+	if err != nil {
+		return nil, err
+	}
+	return LoadDevCA(&FabWallet{Wallet: *w, FabNet: *fabnet})
+}
+
+// LoadDevCA 加载由 NewDevCA 生成并保存的开发用 CA
+func LoadDevCA(fw *FabWallet) (*DevCA, error) {
+	cert, err := fw.Certificate()
+	if err != nil {
+		return nil, err
+	}
+	if !cert.IsCA || !bytes.Equal(cert.RawSubject, cert.RawIssuer) {
+		return nil, errors.Errorf("网络 %s 的身份证书不是自签名根证书", fw.FabMSP.Network)
+	}
+	if !certMatchesKey(cert, fw.PublicKey()) {
+		return nil, ErrCertKeyMismatch
+	}
+	return &DevCA{fw: fw, cert: cert}, nil
+}
+
+// KeyStore CA 账户所在的密钥存储
+func (ca *DevCA) KeyStore() keystore.KeyStore {
+	return ca.fw.KeyStore
+}
+
+// CertPEM 根证书
+func (ca *DevCA) CertPEM() string {
+	return ca.fw.SignCert
+}
+
+// Issue 为证书请求（DER 或 PEM）签发证书，证书主题取自证书请求，返回 PEM 证书
+func (ca *DevCA) Issue(csr []byte, opts *IssueOpts) ([]byte, error) {
+	if block, _ := pem.Decode(csr); block != nil {
+		csr = block.Bytes
+	}
+	req, err := x509.ParseCertificateRequest(csr)
+	if err != nil {
+		return nil, errors.Wrap(err, "解析证书请求失败")
+	}
+	if err = req.CheckSignature(); err != nil {
+		return nil, errors.Wrap(err, "证书请求签名无效")
+	}
+	if opts == nil {
+		opts = &IssueOpts{}
+	}
+	template := x509Template()
+	template.Subject = req.Subject
+	if len(opts.OUs) != 0 {
+		template.Subject.OrganizationalUnit = opts.OUs
+	}
+	template.DNSNames = append(req.DNSNames, opts.DNSNames...)
+	template.IPAddresses = append(req.IPAddresses, opts.IPAddresses...)
+	template.EmailAddresses = append(req.EmailAddresses, opts.EmailAddresses...)
+	template.ExtraExtensions = csrExtensions(req)
+	template.AuthorityKeyId = ca.cert.SubjectKeyId
+	if opts.Expiry > 0 {
+		template.NotAfter = template.NotBefore.Add(opts.Expiry)
+	}
+	if template.NotAfter.After(ca.cert.NotAfter) {
+		template.NotAfter = ca.cert.NotAfter
+	}
+	switch opts.Usage {
+	case "", CertUsageClient:
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+	case CertUsageTLS:
+		template.KeyUsage |= x509.KeyUsageKeyEncipherment
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+	default:
+		return nil, errors.Errorf("未知的证书用途 %s", opts.Usage)
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, req.PublicKey, ca.fw.private)
+	if err != nil {
+		return nil, errors.Wrap(err, "签发证书失败")
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), nil
+}
+
+// Enroll 为同一密钥存储中的钱包签发身份证书，并以 CA 的网络名称保存到该钱包账户网络信息中
+func (ca *DevCA) Enroll(w *Wallet, opts *IssueOpts) (*FabWallet, error) {
+	fw := &FabWallet{Wallet: *w}
+	fw.Org = ca.fw.Org
+	csr, err := fw.CertRequest(nil)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := ca.Issue(csr, opts)
+	if err != nil {
+		return nil, err
+	}
+	resp := &fabca.EnrollResponse{
+		Cert:    cert,
+		CAChain: []byte(ca.CertPEM()),
+	}
+	return w.saveEnrollment(ca.fw.FabMSP.Network, ca.fw.OrgMSP, resp, ca.CertPEM())
+}
+
+// WriteOrgMSP 生成组织 MSP 目录（cacerts、tlscacerts 及启用 NodeOUs 的 config.yaml），用于测试网络的通道配置
+func (ca *DevCA) WriteOrgMSP(dir string) error {
+	if _, err := os.Stat(dir); err == nil {
+		return errors.Errorf("目录 %s 已存在", dir)
+	}
+	for _, sub := range []string{MSPCACerts, MSPTLSCACerts} {
+		err := os.MkdirAll(filepath.Join(dir, sub), keystore.DirPerm)
+		if err != nil {
+			return err
+		}
+		err = ioutil.WriteFile(filepath.Join(dir, sub, mspCACertName(0)), []byte(ca.CertPEM()), keystore.FilePerm)
+		if err != nil {
+			return err
+		}
+	}
+	return WriteNodeOUConfig(dir)
+}
+
+// ski Fabric 使用的主题密钥标识：公钥未压缩编码的 SHA-256
+func ski(w *Wallet) []byte {
+	h := sha256.Sum256(fromECDSAPub(&w.private.PublicKey))
+	return h[:]
+}
+
+// csrExtensions 签发证书时仅沿用证书请求中的 Fabric 证书属性扩展，
+// 基本约束、密钥用途等扩展由 CA 决定，不能由申请者指定
+func csrExtensions(req *x509.CertificateRequest) []pkix.Extension {
+	var exts []pkix.Extension
+	for _, ext := range req.Extensions {
+		if ext.Id.Equal(fabricAttrOID) {
+			exts = append(exts, ext)
+		}
+	}
+	return exts
+}
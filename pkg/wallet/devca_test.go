@@ -0,0 +1,69 @@
+package wallet
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"testing"
+
+	"bewallet/pkg/keystore"
+)
+
+func TestDevCAIssueIgnoresRequestedConstraints(t *testing.T) {
+	ks := keystore.NewMemKeyStore()
+	w, err := CreateWallet(ks, "ca")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, err := w.NewDevCA("dev", "Org1MSP", &DevCAOpts{Org: "org1.example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := ecdsa.GenerateKey(Curve, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	basic, _ := asn1.Marshal(struct{ IsCA bool }{true})
+	attrs := []byte(`{"attrs":{"role":"auditor"}}`)
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject: pkix.Name{CommonName: "evil"},
+		ExtraExtensions: []pkix.Extension{
+			{Id: asn1.ObjectIdentifier{2, 5, 29, 19}, Critical: true, Value: basic},
+			{Id: fabricAttrOID, Value: attrs},
+		},
+	}, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM, err := ca.Issue(csr, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := parseCertPEM(certPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cert.IsCA {
+		t.Fatal("证书请求中的基本约束不应生效")
+	}
+	if len(cert.ExtKeyUsage) != 1 || cert.ExtKeyUsage[0] != x509.ExtKeyUsageClientAuth {
+		t.Fatalf("客户端证书扩展密钥用途错误: %v", cert.ExtKeyUsage)
+	}
+	found := false
+	for _, ext := range cert.Extensions {
+		if ext.Id.Equal(fabricAttrOID) && string(ext.Value) == string(attrs) {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatal("应保留 Fabric 证书属性扩展")
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	if _, err = cert.Verify(x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}}); err != nil {
+		t.Fatal(err)
+	}
+}
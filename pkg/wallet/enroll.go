@@ -0,0 +1,155 @@
+package wallet
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"os"
+
+	"github.com/pkg/errors"
+
+	"bewallet/pkg/fabca"
+)
+
+// Enroll 使用钱包私钥（不生成新私钥）向 Fabric CA 登记，证书及 CA 证书链保存到账户 network 网络身份中，
+// network 为空时使用 mspID
+func (w *Wallet) Enroll(client *fabca.Client, network, mspID, enrollID, secret string, attrs ...fabca.AttributeRequest) (*FabWallet, error) {
+	if len(mspID) == 0 {
+		return nil, errors.New("缺少 MSP ID")
+	}
+	csr, err := w.enrollCSR(enrollID)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Enroll(enrollID, secret, csr, attrs...)
+	if err != nil {
+		return nil, err
+	}
+	if len(network) == 0 {
+		network = mspID
+	}
+	return w.saveEnrollment(network, mspID, resp)
+}
+
+// Reenroll 使用当前证书向 Fabric CA 重新登记，更新账户网络身份中的证书及 CA 证书链
+func (fw *FabWallet) Reenroll(client *fabca.Client, attrs ...fabca.AttributeRequest) error {
+	cert, err := fw.signCert()
+	if err != nil {
+		return err
+	}
+	csr, err := fw.enrollCSR(cert.Subject.CommonName)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Reenroll(fw.identity(), csr, attrs...)
+	if err != nil {
+		return err
+	}
+	updated, err := fw.Wallet.saveEnrollment(fw.FabMSP.Network, fw.OrgMSP, resp)
+	if err != nil {
+		return err
+	}
+	fw.FabNet = updated.FabNet
+	return nil
+}
+
+// Register 以当前身份作为管理员在 Fabric CA 注册新身份，返回登记口令
+func (fw *FabWallet) Register(client *fabca.Client, req *fabca.RegistrationRequest) (string, error) {
+	if _, err := fw.signCert(); err != nil {
+		return "", err
+	}
+	return client.Register(fw.identity(), req)
+}
+
+func (fw *FabWallet) identity() *fabca.Identity {
+	return &fabca.Identity{
+		Cert:   []byte(fw.SignCert),
+		Signer: &fw.Wallet,
+	}
+}
+
+func (fw *FabWallet) signCert() (*x509.Certificate, error) {
+	if len(fw.SignCert) == 0 {
+		return nil, errors.New("账户在该网络中没有身份证书，请先登记")
+	}
+	return parseCertPEM([]byte(fw.SignCert))
+}
+
+// enrollCSR 生成通用名为登记 ID 的证书请求，Fabric CA 要求两者一致
+func (w *Wallet) enrollCSR(enrollID string) ([]byte, error) {
+	if len(enrollID) == 0 {
+		return nil, errors.New("缺少登记 ID")
+	}
+	template := &x509.CertificateRequest{
+		Subject: pkix.Name{CommonName: enrollID},
+	}
+	return x509.CreateCertificateRequest(rand.Reader, template, w.private)
+}
+
+// saveEnrollment 校验登记结果并保存到账户网络信息中，保留已有的节点配置，指定 tlsCACerts 时替换 TLS 根证书
+func (w *Wallet) saveEnrollment(network, mspID string, resp *fabca.EnrollResponse, tlsCACerts ...string) (*FabWallet, error) {
+	cert, err := parseCertPEM(resp.Cert)
+	if err != nil {
+		return nil, errors.WithMessage(err, "CA 返回的证书无效")
+	}
+	if !certMatchesKey(cert, &w.private.PublicKey) {
+		return nil, errors.WithMessage(ErrCertKeyMismatch, "CA 返回的证书无效")
+	}
+	nets, err := LoadFabNet(w.KeyStore, w.name)
+	if err != nil && !os.IsNotExist(errors.Cause(err)) {
+		return nil, err
+	}
+	if nets == nil {
+		nets = make(map[string]*FabNet)
+	}
+	fabnet, ok := nets[network]
+	if !ok {
+		fabnet = &FabNet{Network: Network{Name: network}}
+		nets[network] = fabnet
+	}
+	fabnet.FabMSP.Network = network
+	fabnet.OrgMSP = mspID
+	fabnet.SignCert = string(resp.Cert)
+	fabnet.Org = ""
+	if len(cert.Subject.Organization) != 0 {
+		fabnet.Org = cert.Subject.Organization[0]
+	}
+	fabnet.CACerts, fabnet.IntermediateCerts = splitCAChain(resp.CAChain)
+	if len(tlsCACerts) != 0 {
+		fabnet.TLSCACerts = tlsCACerts
+	}
+	err = SaveFabNet(w.KeyStore, w.name, nets)
+	if err != nil {
+		return nil, err
+	}
+	return &FabWallet{
+		Wallet: *w,
+		FabNet: *fabnet,
+	}, nil
+}
+
+// splitCAChain 将 PEM 证书链拆分为根证书（自签名）和中间证书
+func splitCAChain(chain []byte) (roots, intermediates []string) {
+	for {
+		var block *pem.Block
+		block, chain = pem.Decode(chain)
+		if block == nil {
+			return
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			continue
+		}
+		p := string(pem.EncodeToMemory(block))
+		if bytes.Equal(cert.RawSubject, cert.RawIssuer) && cert.CheckSignatureFrom(cert) == nil {
+			roots = append(roots, p)
+		} else {
+			intermediates = append(intermediates, p)
+		}
+	}
+}
@@ -1,11 +1,11 @@
 package wallet
 
 import (
+	"crypto/ecdsa"
 	"crypto/rand"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/json"
-	"fmt"
 	"math/big"
 	"time"
 
@@ -22,6 +22,10 @@ type FabMSP struct {
 	OrgMSP   string
 	Org      string
 	SignCert string
+
+	CACerts           []string `json:",omitempty"` // MSP 根证书
+	IntermediateCerts []string `json:",omitempty"` // MSP 中间证书
+	TLSCACerts        []string `json:",omitempty"` // MSP TLS 根证书
 }
 
 // Serialize 证书序列化
@@ -45,9 +49,10 @@ type FabNet struct {
 
 // Network fabric 网络
 type Network struct {
-	Name     string  `json:"name,omitempty"`
-	Peers    []*Node `json:"peers,omitempty"`
-	Orderers []*Node `json:"orderers,omitempty"`
+	Name     string   `json:"name,omitempty"`
+	Peers    []*Node  `json:"peers,omitempty"`
+	Orderers []*Node  `json:"orderers,omitempty"`
+	Channels []string `json:"channels,omitempty"` // 网络中的通道
 }
 
 // Node fabric 节点
@@ -74,15 +79,12 @@ type FabWallet struct {
 // 	return hash.Sum(nil)
 // }
 
-// CertRequest 基于网络信息生成证书请求
-func (fw FabWallet) CertRequest() ([]byte, error) {
-	template := &x509.CertificateRequest{}
-	subject := pkix.Name{
-		OrganizationalUnit: []string{"client"},
-		Organization:       []string{fw.Org},
-		CommonName:         fmt.Sprintf("%s@%s", fw.Address(), fw.Org),
+// CertRequest 基于网络信息生成 DER 格式证书请求，opts 为 nil 时生成 OU=client、O=<组织>、CN=<地址>@<组织> 的证书请求
+func (fw FabWallet) CertRequest(opts *CSROpts) ([]byte, error) {
+	template, err := fw.csrTemplate(opts)
+	if err != nil {
+		return nil, err
 	}
-	template.Subject = subject
 	return x509.CreateCertificateRequest(rand.Reader, template, fw.private)
 }
 
@@ -91,20 +93,47 @@ func (fw *FabNet) SetNetwork(net Network) {
 	fw.Network = net
 }
 
-// AddPeers ..
+// AddPeers 添加 peer 节点，地址已存在的节点被替换
 func (fw *FabNet) AddPeers(peers []*Node) {
-	fw.Peers = append(fw.Peers, peers...)
+	fw.Peers = mergeNodes(fw.Peers, peers)
 }
 
-// AddOrderers ..
+// AddOrderers 添加 orderer 节点，地址已存在的节点被替换
 func (fw *FabNet) AddOrderers(orderers []*Node) {
-	fw.Peers = append(fw.Orderers, orderers...)
+	fw.Orderers = mergeNodes(fw.Orderers, orderers)
 }
 
-// SetSignCert  设置钱包认证证书
-func (fw *FabNet) SetSignCert(orgmsp string, cert string) {
+// SetSignCert 设置身份证书，校验证书格式、证书公钥是否为 pub、有效期、是否由 MSP 根证书签发及 MSP 是否与已有身份一致，
+// 证书包含组织信息且未设置组织时以证书中的组织为准
+func (fw *FabNet) SetSignCert(pub *ecdsa.PublicKey, orgmsp string, cert string) error {
+	if len(orgmsp) == 0 {
+		return errors.New("缺少 MSP ID")
+	}
+	if len(fw.OrgMSP) != 0 && fw.OrgMSP != orgmsp {
+		return errors.WithMessagef(ErrCertOrgMSPChange, "已有 %s，设置 %s", fw.OrgMSP, orgmsp)
+	}
+	c, err := parseCertPEM([]byte(cert))
+	if err != nil {
+		return err
+	}
+	if pub == nil || !certMatchesKey(c, pub) {
+		return ErrCertKeyMismatch
+	}
+	err = fw.checkCert(c, time.Now())
+	if err != nil {
+		return err
+	}
 	fw.OrgMSP = orgmsp
 	fw.SignCert = cert
+	if len(fw.Org) == 0 && len(c.Subject.Organization) != 0 {
+		fw.Org = c.Subject.Organization[0]
+	}
+	return nil
+}
+
+// SetSignCert 设置身份证书，证书公钥须与钱包私钥匹配
+func (fw *FabWallet) SetSignCert(orgmsp string, cert string) error {
+	return fw.FabNet.SetSignCert(fw.PublicKey(), orgmsp, cert)
 }
 
 // Serialize 。
@@ -132,6 +161,9 @@ func LoadFabNet(ks keystore.KeyStore, name string) (map[string]*FabNet, error) {
 		return nil, err
 	}
 	nets := make(map[string]*FabNet)
+	if len(data) == 0 {
+		return nets, nil
+	}
 	err = json.Unmarshal(data, &nets)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,47 @@
+package wallet
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"bewallet/pkg/keystore"
+)
+
+func TestSetSignCertChecksKey(t *testing.T) {
+	ks := keystore.NewMemKeyStore()
+	alice, err := CreateWallet(ks, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, err := CreateWallet(ks, "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	caPEM := writeTestMSP(t, dir, alice.private, nil)
+	data, err := ioutil.ReadFile(filepath.Join(dir, MSPSignCerts, "user1-cert.pem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert := string(data)
+
+	fabnet := &FabNet{FabMSP: FabMSP{CACerts: []string{caPEM}}}
+	if err := fabnet.SetSignCert(bob.PublicKey(), "Org1MSP", cert); err != ErrCertKeyMismatch {
+		t.Fatalf("证书公钥与私钥不匹配时应返回 ErrCertKeyMismatch，实际为 %v", err)
+	}
+	if err := fabnet.SetSignCert(nil, "Org1MSP", cert); err != ErrCertKeyMismatch {
+		t.Fatalf("缺少公钥时应返回 ErrCertKeyMismatch，实际为 %v", err)
+	}
+	if len(fabnet.SignCert) != 0 {
+		t.Fatal("校验失败时不应设置证书")
+	}
+	if err := fabnet.SetSignCert(alice.PublicKey(), "Org1MSP", cert); err != nil {
+		t.Fatal(err)
+	}
+
+	fw := &FabWallet{Wallet: *bob, FabNet: FabNet{FabMSP: FabMSP{CACerts: []string{caPEM}}}}
+	if err := fw.SetSignCert("Org1MSP", cert); err != ErrCertKeyMismatch {
+		t.Fatalf("FabWallet.SetSignCert 应校验钱包私钥，实际为 %v", err)
+	}
+}
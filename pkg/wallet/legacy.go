@@ -0,0 +1,82 @@
+package wallet
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+
+	"github.com/pkg/errors"
+)
+
+// 早期版本直接以 BIP-39 种子作为随机源调用 ecdsa.GenerateKey 生成私钥，
+// 结果取决于 Go 工具链中 GenerateKey 读取随机数的方式。以下按各版本工具链的
+// 实现显式还原该过程，使早期钱包不依赖当前工具链也能由助记词恢复。
+
+// legacyVersions 早期密钥生成方式，按检测顺序排列
+var legacyVersions = []string{
+	KeyVersionLegacy,
+	KeyVersionLegacyGo120,
+	KeyVersionLegacyGo120Shift,
+}
+
+// legacyKey 按早期版本方式由种子生成私钥
+func legacyKey(version string, seed []byte) (*ecdsa.PrivateKey, error) {
+	var k *big.Int
+	switch version {
+	case KeyVersionLegacy:
+		k = reduceFieldElement(seed)
+	case KeyVersionLegacyGo120:
+		k = sampleFieldElement(seed)
+	case KeyVersionLegacyGo120Shift:
+		k = sampleFieldElement(seed[1:])
+	default:
+		return nil, errors.Errorf("未知的密钥派生版本 %s", version)
+	}
+	if k == nil {
+		return nil, errors.Errorf("种子长度不足，无法按 %s 方式生成私钥", version)
+	}
+	return scalarToPrivateKey(k), nil
+}
+
+// detectLegacyVersion 找出能由种子重新生成私钥 d 的早期版本，找不到时返回空字符串
+func detectLegacyVersion(seed []byte, d *big.Int) string {
+	for _, v := range legacyVersions {
+		pri, err := legacyKey(v, seed)
+		if err == nil && pri.D.Cmp(d) == 0 {
+			return v
+		}
+	}
+	return ""
+}
+
+// reduceFieldElement go1.19 及以前：读取 BitSize/8+8 字节，k = b mod (N-1) + 1
+func reduceFieldElement(seed []byte) *big.Int {
+	params := Curve.Params()
+	size := params.BitSize/8 + 8
+	if len(seed) < size {
+		return nil
+	}
+	one := big.NewInt(1)
+	k := new(big.Int).SetBytes(seed[:size])
+	n := new(big.Int).Sub(params.N, one)
+	k.Mod(k, n)
+	k.Add(k, one)
+	return k
+}
+
+// sampleFieldElement go1.20 及以后：每次读取 N 的字节长度，落在 (0, N) 内即采用，否则继续读取
+func sampleFieldElement(seed []byte) *big.Int {
+	params := Curve.Params()
+	size := (params.N.BitLen() + 7) / 8
+	excess := size*8 - params.N.BitLen()
+	for len(seed) >= size {
+		b := make([]byte, size)
+		copy(b, seed[:size])
+		seed = seed[size:]
+		b[0] >>= excess
+		k := new(big.Int).SetBytes(b)
+		if k.Sign() != 0 && k.Cmp(params.N) < 0 {
+			return k
+		}
+	}
+	return nil
+}
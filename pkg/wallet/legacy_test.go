@@ -0,0 +1,120 @@
+package wallet
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"bewallet/pkg/keystore"
+
+	"github.com/tyler-smith/go-bip39"
+)
+
+const legacyMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+func TestDetectLegacyVersion(t *testing.T) {
+	seed := bip39.NewSeed(legacyMnemonic, "")
+	cases := []struct {
+		version string
+		key     string
+	}{
+		{KeyVersionLegacy, "257a11b355b4e1bb7ec7188fa16821673696173071289736a38eddee5505b791"},
+		{KeyVersionLegacyGo120, "5eb00bbddcf069084889a8ab9155568165f5c453ccb85e70811aaed6f6da5fc1"},
+		{KeyVersionLegacyGo120Shift, "b00bbddcf069084889a8ab9155568165f5c453ccb85e70811aaed6f6da5fc19a"},
+	}
+	for _, c := range cases {
+		pri, err := legacyKey(c.version, seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hex.EncodeToString(pri.D.FillBytes(make([]byte, 32))); got != c.key {
+			t.Fatalf("%s 私钥为 %s，应为 %s", c.version, got, c.key)
+		}
+		if v := detectLegacyVersion(seed, pri.D); v != c.version {
+			t.Fatalf("应识别为 %s，实际为 %q", c.version, v)
+		}
+	}
+
+	other, err := ecdsa.GenerateKey(Curve, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := detectLegacyVersion(seed, other.D); v != "" {
+		t.Fatalf("无关私钥不应识别出版本，实际为 %s", v)
+	}
+}
+
+func TestSampleFieldElementRejects(t *testing.T) {
+	seed := append(bytes.Repeat([]byte{0xff}, 32), bytes.Repeat([]byte{0x01}, 32)...)
+	k := sampleFieldElement(seed)
+	if k == nil || !bytes.Equal(k.FillBytes(make([]byte, 32)), seed[32:]) {
+		t.Fatalf("不小于 N 的候选值应被丢弃并继续读取，实际为 %x", k)
+	}
+	if sampleFieldElement(seed[:32]) != nil {
+		t.Fatal("种子不足时应返回 nil")
+	}
+	if reduceFieldElement(seed[:39]) != nil {
+		t.Fatal("种子不足 40 字节时应返回 nil")
+	}
+}
+
+// storeEarlyWallet 按早期格式（不记录派生版本）保存钱包
+func storeEarlyWallet(t *testing.T, ks keystore.KeyStore, name string, pri *ecdsa.PrivateKey) {
+	raw, err := x509.MarshalECPrivateKey(pri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sec, err := json.Marshal(Secret{Key: raw, Mnemonic: legacyMnemonic})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ks.Store(&keystore.SecretStoreOpt{Name: name, Content: sec})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMigrateEarlyWallet(t *testing.T) {
+	ks, err := keystore.NewFilKeyStore(t.TempDir(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pri, err := legacyKey(KeyVersionLegacyGo120, bip39.NewSeed(legacyMnemonic, ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	storeEarlyWallet(t, ks, "legacy", pri)
+	other, err := ecdsa.GenerateKey(Curve, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	storeEarlyWallet(t, ks, "other", other)
+
+	for name, version := range map[string]string{"legacy": KeyVersionLegacyGo120, "other": KeyVersionUnknown} {
+		w, err := LoadWallet(ks, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if w.KeyVersion() != version {
+			t.Fatalf("%s 应识别为 %s，实际为 %s", name, version, w.KeyVersion())
+		}
+		raw, err := ks.Load(&keystore.SecretLoadOpt{Name: name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		sec := &Secret{}
+		if err := json.Unmarshal(raw, sec); err != nil {
+			t.Fatal(err)
+		}
+		if sec.Version != version {
+			t.Fatalf("%s 的派生版本应已写入密钥存储，实际为 %q", name, sec.Version)
+		}
+		if w.Recoverable() != (version != KeyVersionUnknown) {
+			t.Fatalf("%s 的 Recoverable 为 %v", name, w.Recoverable())
+		}
+	}
+}
@@ -1,72 +1,402 @@
 package wallet
 
 import (
+	"log"
+	"sort"
+	"sync"
+	"time"
+
 	"bewallet/pkg/fab/sdk"
 	"bewallet/pkg/keystore"
 
 	"github.com/pkg/errors"
 )
 
-// Manager 钱包管理
+// EventType 账户变更类型
+type EventType string
+
+// 账户变更类型
+const (
+	EventCreated   EventType = "created"
+	EventRecovered EventType = "recovered"
+	EventImported  EventType = "imported"
+	EventRemoved   EventType = "removed"
+	EventRenamed   EventType = "renamed"
+	EventArchived  EventType = "archived"
+	EventReloaded  EventType = "reloaded"
+	EventNetworks  EventType = "networks" // 账户网络信息变更
+)
+
+// Event 账户变更通知，EventReloaded 时 Addr、Name 为空
+type Event struct {
+	Type    EventType
+	Addr    string
+	Name    string
+	OldName string // 仅 EventRenamed
+}
+
+// Listener 账户变更监听函数，在变更完成后同步调用，调用时不持有 Manager 的锁
+type Listener func(e Event)
+
+// Manager 钱包管理，负责账户的创建、恢复、导入及删除，并发安全。
+// 账户可以通过名称或地址查找，名称及地址在全部账户中唯一，且名称不能与其他账户的地址相同。
+// 通过 Manager 之外的方式修改密钥存储后需调用 Reload
 type Manager struct {
+	mu        sync.RWMutex
+	accounts  *accountIndex
+	ks        keystore.KeyStore
+	listeners map[int]Listener
+	nextID    int
+}
+
+// accountIndex 账户索引，wallets、networks、metas 以地址为键
+type accountIndex struct {
 	wallets  map[string]*Wallet
 	networks map[string]map[string]*FabNet
-	ks       keystore.KeyStore
+	metas    map[string]*accountMeta // 早期账户在首次修改元数据前没有元数据
+	names    map[string]string       // 名称 -> 地址
+	skipped  map[string]error        // 加载时因名称或地址冲突跳过的账户
+}
+
+func newAccountIndex() *accountIndex {
+	return &accountIndex{
+		wallets:  make(map[string]*Wallet),
+		networks: make(map[string]map[string]*FabNet),
+		metas:    make(map[string]*accountMeta),
+		names:    make(map[string]string),
+		skipped:  make(map[string]error),
+	}
+}
+
+// find 按地址或名称查找账户
+func (ai *accountIndex) find(account string) (*Wallet, bool) {
+	if w, ok := ai.wallets[account]; ok {
+		return w, true
+	}
+	if addr, ok := ai.names[account]; ok {
+		return ai.wallets[addr], true
+	}
+	return nil, false
+}
+
+// checkName 检查 addr 账户能否使用名称 name
+func (ai *accountIndex) checkName(name, addr string) error {
+	if other, ok := ai.names[name]; ok && other != addr {
+		return errors.Errorf("账户 %s 已存在", name)
+	}
+	if w, ok := ai.wallets[name]; ok && w.addr != addr {
+		return errors.Errorf("账户名称 %s 与账户 %s 的地址相同", name, w.name)
+	}
+	return nil
+}
+
+// add 加入账户，名称或地址冲突时返回错误
+func (ai *accountIndex) add(w *Wallet, nets map[string]*FabNet, meta *accountMeta) error {
+	if exist, ok := ai.wallets[w.addr]; ok {
+		return errors.Errorf("账户地址 %s 已被账户 %s 使用", w.addr, exist.name)
+	}
+	if err := ai.checkName(w.name, w.addr); err != nil {
+		return err
+	}
+	ai.wallets[w.addr] = w
+	ai.networks[w.addr] = nets
+	ai.metas[w.addr] = meta
+	ai.names[w.name] = w.addr
+	return nil
+}
+
+func (ai *accountIndex) remove(w *Wallet) {
+	delete(ai.wallets, w.addr)
+	delete(ai.networks, w.addr)
+	delete(ai.metas, w.addr)
+	delete(ai.names, w.name)
 }
 
 // NewManager ..
 func NewManager(ks keystore.KeyStore) (*Manager, error) {
 	m := &Manager{
-		ks:       ks,
-		wallets:  make(map[string]*Wallet, 0),
-		networks: make(map[string]map[string]*FabNet),
+		ks:        ks,
+		listeners: make(map[int]Listener),
 	}
-	err := m.loadWallet()
+	var err error
+	m.accounts, err = m.loadWallet()
 	if err != nil {
 		return nil, err
 	}
 	return m, nil
 }
 
-// AccountList ...
+// Subscribe 注册账户变更监听，返回取消监听的函数
+func (m *Manager) Subscribe(l Listener) (cancel func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	id := m.nextID
+	m.nextID++
+	m.listeners[id] = l
+	return func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		delete(m.listeners, id)
+	}
+}
+
+// notify 通知监听者，调用前需释放锁
+func (m *Manager) notify(e Event) {
+	m.mu.RLock()
+	ids := make([]int, 0, len(m.listeners))
+	for id := range m.listeners {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	listeners := make([]Listener, 0, len(ids))
+	for _, id := range ids {
+		listeners = append(listeners, m.listeners[id])
+	}
+	m.mu.RUnlock()
+	for _, l := range listeners {
+		l(e)
+	}
+}
+
+// Create 创建账户，name 已存在时返回错误
+func (m *Manager) Create(name string, opts ...Option) (*Wallet, error) {
+	return m.add(EventCreated, name, func() (*Wallet, error) {
+		return CreateWallet(m.ks, name, opts...)
+	})
+}
+
+// Recover 由助记词恢复账户，name 已存在或地址已被其他账户使用时返回错误
+func (m *Manager) Recover(name, mnemonic string, opts ...Option) (*Wallet, error) {
+	return m.add(EventRecovered, name, func() (*Wallet, error) {
+		return RecoverWallet(m.ks, name, mnemonic, opts...)
+	})
+}
+
+// Import 导入 Web3 Secret Storage（keystore v3）JSON 格式的私钥，name 已存在或地址已被其他账户使用时返回错误
+func (m *Manager) Import(name string, data []byte, password string) (*Wallet, error) {
+	return m.add(EventImported, name, func() (*Wallet, error) {
+		return ImportWeb3Key(m.ks, name, data, password)
+	})
+}
+
+// add 持有写锁生成并保存账户后加入管理，名称或地址冲突时删除新保存的账户
+func (m *Manager) add(event EventType, name string, gen func() (*Wallet, error)) (*Wallet, error) {
+	m.mu.Lock()
+	if len(name) != 0 {
+		_, ok := m.accounts.find(name)
+		if _, skipped := m.accounts.skipped[name]; ok || skipped {
+			m.mu.Unlock()
+			return nil, errors.Errorf("账户 %s 已存在", name)
+		}
+	}
+	w, err := gen()
+	if err != nil {
+		m.mu.Unlock()
+		return nil, err
+	}
+	// 名称为空时以地址为名，可能覆盖了同名账户，此时不能删除
+	_, replaced := m.accounts.names[w.name]
+	if _, skipped := m.accounts.skipped[w.name]; skipped {
+		replaced = true
+	}
+	w, err = m.track(w)
+	if err != nil {
+		m.mu.Unlock()
+		if !replaced {
+			if derr := m.ks.Delete(w.name); derr != nil {
+				return nil, errors.WithMessagef(err, "清理账户 %s 失败：%v", w.name, derr)
+			}
+		}
+		return nil, err
+	}
+	m.mu.Unlock()
+
+	m.notify(Event{Type: event, Addr: w.addr, Name: w.name})
+	return w, nil
+}
+
+// track 加载账户网络信息及元数据并加入索引，调用时需持有写锁
+func (m *Manager) track(w *Wallet) (*Wallet, error) {
+	if exist, ok := m.accounts.wallets[w.addr]; ok {
+		return w, errors.Errorf("账户地址 %s 已被账户 %s 使用", w.addr, exist.name)
+	}
+	nets, err := LoadFabNet(m.ks, w.name)
+	if err != nil {
+		return w, err
+	}
+	meta, err := w.newMeta()
+	if err != nil {
+		return w, err
+	}
+	return w, m.accounts.add(w, nets, meta)
+}
+
+// Reload 从密钥存储重新加载全部账户，加载失败时保持原有数据不变
+func (m *Manager) Reload() error {
+	accounts, err := m.loadWallet()
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	m.accounts = accounts
+	m.mu.Unlock()
+
+	m.notify(Event{Type: EventReloaded})
+	return nil
+}
+
+// AccountList 账户地址 -> 账户名称
 func (m *Manager) AccountList() map[string]string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	list := make(map[string]string)
-	for _, w := range m.wallets {
+	for _, w := range m.accounts.wallets {
 		list[w.addr] = w.name // 用户名作为可选项
 	}
 	return list
 }
 
-// GetWallet ..
-func (m *Manager) GetWallet(addr string) *ModelWallet {
-	if w, ok := m.wallets[addr]; ok {
+// Skipped 加载时因名称或地址与其他账户冲突而跳过的账户，账户名称 -> 原因，
+// 可在密钥存储中改名或删除后调用 Reload
+func (m *Manager) Skipped() map[string]error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	skipped := make(map[string]error, len(m.accounts.skipped))
+	for k, v := range m.accounts.skipped {
+		skipped[k] = v
+	}
+	return skipped
+}
+
+// Accounts 列出全部账户，按创建时间及名称排序
+func (m *Manager) Accounts() []*Account {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	list := make([]*Account, 0, len(m.accounts.wallets))
+	for addr, w := range m.accounts.wallets {
+		list = append(list, w.account(m.accounts.metas[addr]))
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].Created.Equal(list[j].Created) {
+			return list[i].Created.Before(list[j].Created)
+		}
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// Account 按名称或地址查找账户，不存在时返回 nil
+func (m *Manager) Account(account string) *Account {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	w, ok := m.accounts.find(account)
+	if !ok {
+		return nil
+	}
+	return w.account(m.accounts.metas[w.addr])
+}
+
+// SetLabels 设置账户标签，value 为空的标签被删除
+func (m *Manager) SetLabels(account string, labels map[string]string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	w, ok := m.accounts.find(account)
+	if !ok {
+		return errors.Errorf("账户 %s 不存在", account)
+	}
+	old := m.accounts.metas[w.addr]
+	if old == nil {
+		var err error
+		old, err = w.newMeta()
+		if err != nil {
+			return err
+		}
+		m.accounts.metas[w.addr] = old
+	}
+	meta := *old
+	meta.Labels = make(map[string]string)
+	for k, v := range old.Labels {
+		meta.Labels[k] = v
+	}
+	for k, v := range labels {
+		if len(v) == 0 {
+			delete(meta.Labels, k)
+			continue
+		}
+		meta.Labels[k] = v
+	}
+	err := w.saveMeta(&meta)
+	if err != nil {
+		return err
+	}
+	m.accounts.metas[w.addr] = &meta
+	return nil
+}
+
+// GetWallet 按名称或地址查找账户
+func (m *Manager) GetWallet(account string) *ModelWallet {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if w, ok := m.accounts.find(account); ok {
 		return &ModelWallet{
 			Addr: w.addr,
 			Name: w.name,
+			Path: w.path,
 		}
 	}
 	return nil
 }
 
-// GetNetworks ..
-func (m *Manager) GetNetworks(addr string) map[string]*FabNet {
-	if n, ok := m.networks[addr]; ok {
-		return n
+// DerivedAccounts 列出与 account 由同一助记词派生的全部账户（含 account 自身），按派生路径排序
+func (m *Manager) DerivedAccounts(account string) []*ModelWallet {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	w, ok := m.accounts.find(account)
+	if !ok || len(w.root) == 0 {
+		return nil
 	}
-	return nil
+	list := []*ModelWallet{}
+	for _, d := range m.accounts.wallets {
+		if d.root != w.root {
+			continue
+		}
+		list = append(list, &ModelWallet{
+			Addr: d.addr,
+			Name: d.name,
+			Path: d.path,
+		})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return pathLess(list[i].Path, list[j].Path)
+	})
+	return list
 }
 
-// GetSigner ..
-func (m *Manager) GetSigner(addr, net string) sdk.Signer {
-	w, ok := m.wallets[addr]
+// GetNetworks 按名称或地址查找账户，返回账户网络信息的副本
+func (m *Manager) GetNetworks(account string) map[string]*FabNet {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	w, ok := m.accounts.find(account)
 	if !ok {
 		return nil
 	}
-	nets, ok := m.networks[addr]
+	n := m.accounts.networks[w.addr]
+	nets := make(map[string]*FabNet, len(n))
+	for k, v := range n {
+		nets[k] = copyFabNet(v)
+	}
+	return nets
+}
+
+// GetSigner 按名称或地址查找账户，返回账户在网络 net 中的签名身份，不存在时返回 nil
+func (m *Manager) GetSigner(account, net string) sdk.Signer {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	w, ok := m.accounts.find(account)
 	if !ok {
 		return nil
 	}
-	fabnet, ok := nets[net]
+	fabnet, ok := m.accounts.networks[w.addr][net]
 	if !ok {
 		return nil
 	}
@@ -76,26 +406,148 @@ func (m *Manager) GetSigner(addr, net string) sdk.Signer {
 	}
 }
 
-// LoadWallet 加载钱包
-func (m *Manager) loadWallet() error {
+// Remove 按名称或地址删除账户密钥、网络配置及元数据
+func (m *Manager) Remove(account string) error {
+	m.mu.Lock()
+	w, ok := m.accounts.find(account)
+	if !ok {
+		m.mu.Unlock()
+		return errors.Errorf("账户 %s 不存在", account)
+	}
+	err := m.ks.Delete(w.name)
+	if err != nil {
+		m.mu.Unlock()
+		return err
+	}
+	m.accounts.remove(w)
+	m.mu.Unlock()
 
-	list, err := m.ks.List()
+	m.notify(Event{Type: EventRemoved, Addr: w.addr, Name: w.name})
+	return nil
+}
+
+// Delete 删除账户密钥及网络配置
+//
+// Deprecated: 使用 Remove
+func (m *Manager) Delete(addr string) error {
+	return m.Remove(addr)
+}
+
+// Rename 按名称或地址查找账户并改名
+func (m *Manager) Rename(account, name string) error {
+	m.mu.Lock()
+	w, ok := m.accounts.find(account)
+	if !ok {
+		m.mu.Unlock()
+		return errors.Errorf("账户 %s 不存在", account)
+	}
+	err := m.accounts.checkName(name, w.addr)
 	if err != nil {
+		m.mu.Unlock()
 		return err
 	}
+	oldName := w.name
+	err = m.ks.Rename(oldName, name)
+	if err != nil {
+		m.mu.Unlock()
+		return err
+	}
+	delete(m.accounts.names, oldName)
+	m.accounts.names[name] = w.addr
+	w.name = name
+	m.mu.Unlock()
+
+	m.notify(Event{Type: EventRenamed, Addr: w.addr, Name: name, OldName: oldName})
+	return nil
+}
+
+// Archive 按名称或地址归档账户，归档后不再出现在账户列表中
+func (m *Manager) Archive(account string) error {
+	m.mu.Lock()
+	w, ok := m.accounts.find(account)
+	if !ok {
+		m.mu.Unlock()
+		return errors.Errorf("账户 %s 不存在", account)
+	}
+	err := m.ks.Archive(w.name)
+	if err != nil {
+		m.mu.Unlock()
+		return err
+	}
+	m.accounts.remove(w)
+	m.mu.Unlock()
+
+	m.notify(Event{Type: EventArchived, Addr: w.addr, Name: w.name})
+	return nil
+}
+
+// ExpiringCerts 列出 within 时间内到期（含已过期及无法解析）的身份证书，按到期时间排序
+func (m *Manager) ExpiringCerts(within time.Duration) []*CertExpiry {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	deadline := time.Now().Add(within)
+	list := []*CertExpiry{}
+	for addr, w := range m.accounts.wallets {
+		for name, net := range m.accounts.networks[addr] {
+			if len(net.SignCert) == 0 {
+				continue
+			}
+			ce := &CertExpiry{
+				Addr:    w.addr,
+				Name:    w.name,
+				Network: name,
+			}
+			ce.NotAfter, ce.Err = net.NotAfter()
+			if ce.Err == nil && ce.NotAfter.After(deadline) {
+				continue
+			}
+			list = append(list, ce)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].NotAfter.Equal(list[j].NotAfter) {
+			return list[i].NotAfter.Before(list[j].NotAfter)
+		}
+		if list[i].Name != list[j].Name {
+			return list[i].Name < list[j].Name
+		}
+		return list[i].Network < list[j].Network
+	})
+	return list
+}
 
+// loadWallet 从密钥存储加载全部账户、网络信息及元数据。未记录派生版本的早期钱包
+// 在加载时识别并写回派生版本，写回失败只记录警告，下次加载时重试。
+// 账户按名称顺序加载，与已加载账户的地址或名称冲突的账户被跳过，可通过 Skipped 查看
+func (m *Manager) loadWallet() (*accountIndex, error) {
+	list, err := m.ks.List()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(list)
+	accounts := newAccountIndex()
 	for _, n := range list {
-		w, err := LoadWallet(m.ks, n)
+		w, err := readWallet(m.ks, n)
 		if err != nil {
-			return errors.WithMessagef(err, "加载账户 %s 密钥失败", n)
+			return nil, errors.WithMessagef(err, "加载账户 %s 密钥失败", n)
+		}
+		if len(w.version) == 0 {
+			if err := w.migrate(); err != nil {
+				log.Printf("警告: 记录账户 %s 的派生版本失败: %v", n, err)
+			}
 		}
-		m.wallets[w.addr] = w
-
 		nets, err := LoadFabNet(m.ks, n)
 		if err != nil {
-			return errors.WithMessagef(err, "加载账户网络配置信息失败", n)
+			return nil, errors.WithMessagef(err, "加载账户 %s 网络配置信息失败", n)
+		}
+		meta, err := w.readMeta()
+		if err != nil {
+			return nil, errors.WithMessagef(err, "加载账户 %s 元数据失败", n)
+		}
+		err = accounts.add(w, nets, meta)
+		if err != nil {
+			accounts.skipped[n] = err
 		}
-		m.networks[n] = nets
 	}
-	return nil
+	return accounts, nil
 }
@@ -0,0 +1,284 @@
+package wallet
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"fmt"
+	"sync"
+	"testing"
+
+	"bewallet/pkg/keystore"
+
+	"github.com/pkg/errors"
+)
+
+func TestManagerLifecycle(t *testing.T) {
+	ks := keystore.NewMemKeyStore()
+	m, err := NewManager(ks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mu sync.Mutex
+	var events []EventType
+	cancel := m.Subscribe(func(e Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, e.Type)
+	})
+	defer cancel()
+
+	a, err := m.Create("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = m.Create("a"); err == nil {
+		t.Fatal("重名账户应创建失败")
+	}
+	mnemonic, err := a.RevealMnemonic("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = m.Recover("b", mnemonic); err == nil {
+		t.Fatal("地址重复的账户应恢复失败")
+	}
+	if list, _ := ks.List(); len(list) != 1 {
+		t.Fatalf("恢复失败后应清理新保存的账户，实际为 %v", list)
+	}
+
+	if m.Account("a") == nil || m.Account(a.Address()) == nil {
+		t.Fatal("应可按名称及地址查找账户")
+	}
+	if err = m.Rename("a", "a2"); err != nil {
+		t.Fatal(err)
+	}
+	if m.Account("a") != nil || m.Account("a2").Addr != a.Address() {
+		t.Fatal("改名后查找结果错误")
+	}
+	if err = m.Remove("a2"); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Accounts()) != 0 {
+		t.Fatal("删除后账户列表应为空")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []EventType{EventCreated, EventRenamed, EventRemoved}
+	if len(events) != len(want) {
+		t.Fatalf("事件 %v，应为 %v", events, want)
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Fatalf("事件 %v，应为 %v", events, want)
+		}
+	}
+}
+
+func TestManagerReload(t *testing.T) {
+	ks := keystore.NewMemKeyStore()
+	m, err := NewManager(ks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := CreateWallet(ks, "outside")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Account("outside") != nil {
+		t.Fatal("Reload 前不应看到 Manager 之外创建的账户")
+	}
+	if err = m.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if acc := m.Account("outside"); acc == nil || acc.Addr != w.Address() {
+		t.Fatal("Reload 后应加载 Manager 之外创建的账户")
+	}
+}
+
+func TestManagerSigner(t *testing.T) {
+	ks := keystore.NewMemKeyStore()
+	w, err := CreateWallet(ks, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = SaveFabNet(ks, "a", map[string]*FabNet{"n1": {FabMSP: FabMSP{Network: "n1", OrgMSP: "Org1MSP"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewManager(ks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.GetSigner("a", "n1") == nil || m.GetSigner(w.Address(), "n1") == nil {
+		t.Fatal("应可按名称及地址获取签名身份")
+	}
+	if m.GetSigner("a", "n2") != nil {
+		t.Fatal("不存在的网络不应返回签名身份")
+	}
+}
+
+func TestManagerSkipsDuplicateAddress(t *testing.T) {
+	ks := keystore.NewMemKeyStore()
+	a, err := CreateWallet(ks, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mnemonic, err := a.RevealMnemonic("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = RecoverWallet(ks, "b", mnemonic); err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewManager(ks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Account("a") == nil || m.Account("b") != nil {
+		t.Fatal("应保留 a 并跳过地址重复的 b")
+	}
+	if _, ok := m.Skipped()["b"]; !ok {
+		t.Fatalf("Skipped 应包含 b，实际为 %v", m.Skipped())
+	}
+	if _, err = m.Create("b"); err == nil {
+		t.Fatal("不应覆盖被跳过的账户")
+	}
+	if err = ks.Delete("b"); err != nil {
+		t.Fatal(err)
+	}
+	if err = m.Reload(); err != nil || len(m.Skipped()) != 0 {
+		t.Fatal(err, m.Skipped())
+	}
+}
+
+func TestManagerLoadIsReadOnly(t *testing.T) {
+	ks := keystore.NewMemKeyStore()
+	if _, err := CreateWallet(ks, "a"); err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewManager(ks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := ks.Load(&keystore.MetaLoadOpt{Name: "a"}); data != nil {
+		t.Fatal("加载账户不应写入元数据")
+	}
+	if acc := m.Account("a"); acc.ID != "" || !acc.Created.IsZero() {
+		t.Fatalf("没有元数据的账户 ID 及创建时间应为空: %+v", acc)
+	}
+	if err = m.SetLabels("a", map[string]string{"team": "ops"}); err != nil {
+		t.Fatal(err)
+	}
+	acc := m.Account("a")
+	if acc.ID == "" || acc.Created.IsZero() || acc.Labels["team"] != "ops" {
+		t.Fatalf("设置标签后应生成元数据: %+v", acc)
+	}
+	if err = m.Reload(); err != nil || m.Account("a").ID != acc.ID {
+		t.Fatal("元数据应持久化", err)
+	}
+}
+
+// readOnlyKeyStore 拒绝写入密钥的密钥存储
+type readOnlyKeyStore struct {
+	keystore.KeyStore
+}
+
+func (readOnlyKeyStore) Store(keystore.StoreOpts) error {
+	return errors.New("read-only")
+}
+
+func TestManagerMigrateIsBestEffort(t *testing.T) {
+	ks := keystore.NewMemKeyStore()
+	pri, err := ecdsa.GenerateKey(Curve, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	storeEarlyWallet(t, ks, "early", pri)
+	if _, err := LoadWallet(readOnlyKeyStore{ks}, "early"); err == nil {
+		t.Fatal("LoadWallet 写回派生版本失败时应返回错误")
+	}
+	m, err := NewManager(readOnlyKeyStore{ks})
+	if err != nil {
+		t.Fatal("写回派生版本失败不应影响加载:", err)
+	}
+	if acc := m.Account("early"); acc == nil || acc.Version != KeyVersionUnknown {
+		t.Fatalf("派生版本应为 %s: %+v", KeyVersionUnknown, acc)
+	}
+}
+
+// 并发创建、删除及读取账户，需配合 -race 运行
+func TestManagerConcurrentCreateRemove(t *testing.T) {
+	m, err := NewManager(keystore.NewMemKeyStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	const n = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, 4*n)
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("acc-%d", i)
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if _, err := m.Create(name); err != nil {
+				errs <- err
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			m.Accounts()
+			m.AccountList()
+			m.GetWallet(name)
+		}()
+	}
+	// 同名账户只能创建成功一次
+	created := make(chan bool, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := m.Create("same")
+			created <- err == nil
+		}()
+	}
+	wg.Wait()
+	close(created)
+	ok := 0
+	for c := range created {
+		if c {
+			ok++
+		}
+	}
+	if ok != 1 {
+		t.Fatalf("同名账户创建成功 %d 次", ok)
+	}
+
+	for i := 0; i < n; i += 2 {
+		name := fmt.Sprintf("acc-%d", i)
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if err := m.Remove(name); err != nil {
+				errs <- err
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			m.Accounts()
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+	if got := len(m.Accounts()); got != n/2+1 {
+		t.Fatalf("剩余 %d 个账户，应为 %d", got, n/2+1)
+	}
+	for i := 0; i < n; i++ {
+		exist := m.GetWallet(fmt.Sprintf("acc-%d", i)) != nil
+		if exist != (i%2 == 1) {
+			t.Fatalf("账户 acc-%d 存在状态为 %v", i, exist)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package wallet
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/tyler-smith/go-bip39"
+	"github.com/tyler-smith/go-bip39/wordlists"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Language 助记词词表语言
+type Language string
+
+// 助记词词表语言
+const (
+	LangChineseSimplified  Language = "chinese-simplified"
+	LangChineseTraditional Language = "chinese-traditional"
+	LangEnglish            Language = "english"
+	LangJapanese           Language = "japanese"
+	LangKorean             Language = "korean"
+	LangSpanish            Language = "spanish"
+	LangFrench             Language = "french"
+	LangItalian            Language = "italian"
+	LangCzech              Language = "czech"
+)
+
+// 助记词默认参数
+const (
+	DefaultLanguage  = LangChineseSimplified
+	DefaultWordCount = 12
+)
+
+// 错误
+var (
+	ErrInvalidMnemonic = errors.New("助记词无效")
+	ErrPassphrase      = errors.New("助记词口令错误")
+)
+
+var (
+	// languages 恢复时自动识别语言的检测顺序。简体与繁体中文词表有大量相同的字，
+	// 同时有效时取简体；由于种子只取决于助记词本身，识别结果不影响派生出的密钥
+	languages = []Language{
+		LangChineseSimplified,
+		LangChineseTraditional,
+		LangEnglish,
+		LangJapanese,
+		LangKorean,
+		LangSpanish,
+		LangFrench,
+		LangItalian,
+		LangCzech,
+	}
+
+	wordLists = map[Language][]string{
+		LangChineseSimplified:  wordlists.ChineseSimplified,
+		LangChineseTraditional: wordlists.ChineseTraditional,
+		LangEnglish:            wordlists.English,
+		LangJapanese:           wordlists.Japanese,
+		LangKorean:             wordlists.Korean,
+		LangSpanish:            wordlists.Spanish,
+		LangFrench:             wordlists.French,
+		LangItalian:            wordlists.Italian,
+		LangCzech:              wordlists.Czech,
+	}
+
+	// bip39 的词表是包级全局变量，切换词表及使用词表的操作需加锁
+	bip39Lock sync.Mutex
+)
+
+// normalizeMnemonic 按 BIP-39 要求做 NFKD 规范化，并以单个空格连接各个单词
+func normalizeMnemonic(mnemonic string) string {
+	return strings.Join(strings.Fields(norm.NFKD.String(mnemonic)), " ")
+}
+
+// genMnemonic 生成指定语言和单词数量的助记词
+func genMnemonic(lang Language, words int) (string, error) {
+	list, ok := wordLists[lang]
+	if !ok {
+		return "", errors.Errorf("不支持的助记词语言 %s", lang)
+	}
+	if words%3 != 0 || words < 12 || words > 24 {
+		return "", errors.Errorf("助记词单词数量 %d 非法，可选 12、15、18、21、24", words)
+	}
+	entropy, err := bip39.NewEntropy(words / 3 * 32)
+	if err != nil {
+		return "", err
+	}
+
+	bip39Lock.Lock()
+	defer bip39Lock.Unlock()
+	bip39.SetWordList(list)
+	return bip39.NewMnemonic(entropy)
+}
+
+// checkMnemonic 校验助记词，lang 为空时自动识别语言
+func checkMnemonic(mnemonic string, lang Language) (Language, error) {
+	candidates := languages
+	if len(lang) != 0 {
+		if _, ok := wordLists[lang]; !ok {
+			return "", errors.Errorf("不支持的助记词语言 %s", lang)
+		}
+		candidates = []Language{lang}
+	}
+
+	bip39Lock.Lock()
+	defer bip39Lock.Unlock()
+	for _, l := range candidates {
+		bip39.SetWordList(wordLists[l])
+		if bip39.IsMnemonicValid(mnemonic) {
+			return l, nil
+		}
+	}
+	return "", ErrInvalidMnemonic
+}
@@ -0,0 +1,107 @@
+package wallet
+
+import (
+	"strings"
+	"testing"
+
+	"bewallet/pkg/keystore"
+)
+
+func TestGenMnemonic(t *testing.T) {
+	for _, lang := range languages {
+		for words := 12; words <= 24; words += 3 {
+			m, err := genMnemonic(lang, words)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n := len(strings.Fields(m)); n != words {
+				t.Fatalf("%s 助记词单词数量为 %d，应为 %d", lang, n, words)
+			}
+			got, err := checkMnemonic(normalizeMnemonic(m), lang)
+			if err != nil || got != lang {
+				t.Fatalf("%s 助记词校验失败: %v", lang, err)
+			}
+			if _, err := checkMnemonic(normalizeMnemonic(m), ""); err != nil {
+				t.Fatalf("%s 助记词应能自动识别语言: %v", lang, err)
+			}
+		}
+	}
+	for _, words := range []int{0, 11, 13, 27} {
+		if _, err := genMnemonic(LangEnglish, words); err == nil {
+			t.Fatalf("单词数量 %d 应生成失败", words)
+		}
+	}
+	if _, err := genMnemonic("klingon", 12); err == nil {
+		t.Fatal("不支持的语言应生成失败")
+	}
+}
+
+func TestCheckMnemonic(t *testing.T) {
+	m := "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+	if got := normalizeMnemonic("  abandon\tabandon\u3000about "); got != "abandon abandon about" {
+		t.Fatalf("规范化结果为 %q", got)
+	}
+	if lang, err := checkMnemonic(m, ""); err != nil || lang != LangEnglish {
+		t.Fatalf("应识别为英文助记词: %s %v", lang, err)
+	}
+	if _, err := checkMnemonic(m, LangFrench); err != ErrInvalidMnemonic {
+		t.Fatalf("语言不符时应返回 ErrInvalidMnemonic，实际为 %v", err)
+	}
+	bad := strings.Replace(m, "about", "abandon", 1)
+	if _, err := checkMnemonic(bad, ""); err != ErrInvalidMnemonic {
+		t.Fatalf("校验和错误时应返回 ErrInvalidMnemonic，实际为 %v", err)
+	}
+}
+
+// tempKeyStore 临时目录中不加密的密钥存储
+func tempKeyStore(t *testing.T) keystore.KeyStore {
+	ks, err := keystore.NewFilKeyStore(t.TempDir(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ks
+}
+
+func TestMnemonicOptionsRoundTrip(t *testing.T) {
+	ks := tempKeyStore(t)
+	w, err := CreateWallet(ks, "alice",
+		WithLanguage(LangJapanese),
+		WithWordCount(24),
+		WithPassphrase("TREZOR"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.Language() != LangJapanese || !w.HasPassphrase() || len(strings.Fields(w.mnemonic)) != 24 {
+		t.Fatalf("钱包参数不正确: %s %v %d", w.Language(), w.HasPassphrase(), len(strings.Fields(w.mnemonic)))
+	}
+
+	r, err := RecoverWallet(tempKeyStore(t), "bob", w.mnemonic, WithPassphrase("TREZOR"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Address() != w.Address() || r.Language() != LangJapanese {
+		t.Fatalf("恢复的钱包不一致: %s %s", r.Address(), r.Language())
+	}
+	other, err := RecoverWallet(tempKeyStore(t), "bob", w.mnemonic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other.Address() == w.Address() {
+		t.Fatal("不同的助记词口令应得到不同的钱包")
+	}
+
+	loaded, err := LoadWallet(ks, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Language() != LangJapanese || !loaded.HasPassphrase() {
+		t.Fatal("助记词语言及口令标记应持久化")
+	}
+	if _, err := loaded.Derive("alice-1", 1); err != ErrPassphrase {
+		t.Fatalf("未提供助记词口令时派生应返回 ErrPassphrase，实际为 %v", err)
+	}
+	if _, err := loaded.Derive("alice-1", 1, WithPassphrase("TREZOR")); err != nil {
+		t.Fatal(err)
+	}
+}
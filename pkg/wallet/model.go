@@ -1,7 +1,38 @@
 package wallet
 
+import "time"
+
 // ModelWallet ..
 type ModelWallet struct {
 	Addr string
 	Name string
+	Path string
+}
+
+// CertExpiry 身份证书到期信息
+type CertExpiry struct {
+	Addr     string
+	Name     string
+	Network  string
+	NotAfter time.Time
+	Err      error // 证书无法解析时的错误
+}
+
+// Expired 证书是否已过期或无法解析
+func (ce *CertExpiry) Expired(now time.Time) bool {
+	return ce.Err != nil || now.After(ce.NotAfter)
+}
+
+// Account 账户信息
+type Account struct {
+	ID         string            // 账户唯一标识，改名后不变，早期账户在首次设置标签前为空
+	Name       string            // 账户名称，即密钥存储中的名称
+	Addr       string            // 账户地址
+	Version    string            // 密钥派生版本
+	Path       string            // 密钥派生路径
+	Root       string            // 派生主密钥对应的地址
+	Language   Language          // 助记词语言
+	Passphrase bool              // 是否使用了助记词口令
+	Created    time.Time         // 创建时间，早期账户为首次设置标签的时间，此前为零值
+	Labels     map[string]string // 标签
 }
@@ -0,0 +1,283 @@
+package wallet
+
+import (
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"bewallet/pkg/keystore"
+	"bewallet/pkg/utils"
+)
+
+// MSP 目录结构
+const (
+	MSPSignCerts         = "signcerts"
+	MSPKeyStore          = "keystore"
+	MSPCACerts           = "cacerts"
+	MSPIntermediateCerts = "intermediatecerts"
+	MSPTLSCACerts        = "tlscacerts"
+
+	mspCertFile   = "cert.pem"
+	mspKeyFile    = "priv_sk"
+	mspConfigFile = "config.yaml"
+)
+
+// ImportMSP 导入 cryptogen 或 Fabric CA 生成的 MSP 目录（signcerts、keystore 等）为 name 账户，
+// keystore 中的私钥可以是加密的 PEM，keyPassword 为其口令。导入的身份以 network 为名保存到账户网络信息中，
+// network 为空时使用 mspID
+func ImportMSP(ks keystore.KeyStore, name, dir, mspID, network string, keyPassword []byte) (*FabWallet, error) {
+	if len(mspID) == 0 {
+		return nil, errors.New("缺少 MSP ID")
+	}
+	if len(network) == 0 {
+		network = mspID
+	}
+	certs, err := readCertFiles(filepath.Join(dir, MSPSignCerts))
+	if err != nil {
+		return nil, err
+	}
+	if len(certs) != 1 {
+		return nil, errors.Errorf("目录 %s 中应有且仅有一个证书，实际为 %d 个", filepath.Join(dir, MSPSignCerts), len(certs))
+	}
+	cert, _ := parseCertPEM([]byte(certs[0]))
+	pub, ok := cert.PublicKey.(*ecdsa.PublicKey)
+	if !ok || pub.Curve != Curve {
+		return nil, errors.New("证书公钥不是 P-256 曲线 ECDSA 公钥")
+	}
+	pri, err := findMSPKey(filepath.Join(dir, MSPKeyStore), pub, keyPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	fm := FabMSP{
+		Network:  network,
+		OrgMSP:   mspID,
+		SignCert: certs[0],
+	}
+	if len(cert.Subject.Organization) != 0 {
+		fm.Org = cert.Subject.Organization[0]
+	}
+	for sub, list := range map[string]*[]string{
+		MSPCACerts:           &fm.CACerts,
+		MSPIntermediateCerts: &fm.IntermediateCerts,
+		MSPTLSCACerts:        &fm.TLSCACerts,
+	} {
+		*list, err = readCertFiles(filepath.Join(dir, sub))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	w := &Wallet{
+		KeyStore: ks,
+		private:  pri,
+		addr:     genAddr(pri),
+		name:     name,
+	}
+	if len(w.name) == 0 {
+		w.name = w.addr
+	}
+	// 账户已存在时只添加网络身份，保留其助记词等信息
+	exist, err := LoadWallet(ks, w.name)
+	switch {
+	case err == nil && exist.addr != w.addr:
+		return nil, errors.Errorf("账户 %s 已存在且私钥与 MSP 身份不一致", w.name)
+	case err == nil:
+		w = exist
+	case !os.IsNotExist(errors.Cause(err)):
+		return nil, err
+	default:
+		err = w.store()
+		if err != nil {
+			return nil, err
+		}
+	}
+	nets, err := LoadFabNet(ks, w.name)
+	if err != nil && !os.IsNotExist(errors.Cause(err)) {
+		return nil, err
+	}
+	if nets == nil {
+		nets = make(map[string]*FabNet)
+	}
+	fabnet := &FabNet{
+		FabMSP:  fm,
+		Network: Network{Name: network},
+	}
+	if old, ok := nets[network]; ok {
+		fabnet.Network = old.Network
+	}
+	nets[network] = fabnet
+
+	err = SaveFabNet(ks, w.name, nets)
+	if err != nil {
+		return nil, err
+	}
+	return &FabWallet{
+		Wallet: *w,
+		FabNet: *fabnet,
+	}, nil
+}
+
+// ExportMSP 校验密钥存储口令后按标准 MSP 目录结构导出身份证书及私钥（PKCS#8 PEM，不加密）到 dir
+func (fw *FabWallet) ExportMSP(dir, password string) error {
+	err := fw.verifyPassword(password, "导出私钥")
+	if err != nil {
+		return err
+	}
+	if len(fw.SignCert) == 0 {
+		return errors.New("账户在该网络中没有身份证书")
+	}
+	if _, err := os.Stat(dir); err == nil {
+		return errors.Errorf("目录 %s 已存在", dir)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(fw.private)
+	if err != nil {
+		return err
+	}
+	files := map[string][]byte{
+		filepath.Join(MSPSignCerts, mspCertFile): []byte(fw.SignCert),
+		filepath.Join(MSPKeyStore, mspKeyFile):   pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}),
+	}
+	for sub, list := range map[string][]string{
+		MSPCACerts:           fw.CACerts,
+		MSPIntermediateCerts: fw.IntermediateCerts,
+		MSPTLSCACerts:        fw.TLSCACerts,
+	} {
+		for i, c := range list {
+			files[filepath.Join(sub, mspCACertName(i))] = []byte(c)
+		}
+	}
+	for file, data := range files {
+		path := filepath.Join(dir, file)
+		err = os.MkdirAll(filepath.Dir(path), keystore.DirPerm)
+		if err != nil {
+			return err
+		}
+		err = ioutil.WriteFile(path, data, keystore.FilePerm)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mspCACertName 导出 MSP 时第 i 个 CA 证书的文件名，第一个为 ca.pem
+func mspCACertName(i int) string {
+	if i == 0 {
+		return "ca.pem"
+	}
+	return fmt.Sprintf("ca-%d.pem", i)
+}
+
+// nodeOUConfig 启用 NodeOUs 的 MSP config.yaml，以 cacerts/ca.pem 为各类身份的签发者
+const nodeOUConfig = `NodeOUs:
+  Enable: true
+  ClientOUIdentifier:
+    Certificate: cacerts/ca.pem
+    OrganizationalUnitIdentifier: client
+  PeerOUIdentifier:
+    Certificate: cacerts/ca.pem
+    OrganizationalUnitIdentifier: peer
+  AdminOUIdentifier:
+    Certificate: cacerts/ca.pem
+    OrganizationalUnitIdentifier: admin
+  OrdererOUIdentifier:
+    Certificate: cacerts/ca.pem
+    OrganizationalUnitIdentifier: orderer
+`
+
+// WriteNodeOUConfig 在 MSP 目录中写入启用 NodeOUs 的 config.yaml，身份类型由证书 OU（client、peer、admin、orderer）区分
+func WriteNodeOUConfig(dir string) error {
+	err := os.MkdirAll(dir, keystore.DirPerm)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, mspConfigFile), []byte(nodeOUConfig), keystore.FilePerm)
+}
+
+// findMSPKey 在 keystore 目录中查找与证书公钥匹配的私钥
+func findMSPKey(dir string, pub *ecdsa.PublicKey, password []byte) (*ecdsa.PrivateKey, error) {
+	_, pems, err := readPEMFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	var lastErr error
+	for _, p := range pems {
+		key, err := utils.PEMtoPrivateKey(p, password)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		pri, ok := key.(*ecdsa.PrivateKey)
+		if !ok {
+			continue
+		}
+		if pri.PublicKey.Equal(pub) {
+			return pri, nil
+		}
+	}
+	if lastErr != nil {
+		return nil, errors.WithMessagef(lastErr, "目录 %s 中没有与证书匹配的私钥", dir)
+	}
+	return nil, errors.Errorf("目录 %s 中没有与证书匹配的私钥", dir)
+}
+
+// readCertFiles 读取目录中的全部 PEM 证书，文件不是证书时返回错误，目录不存在时返回空
+func readCertFiles(dir string) ([]string, error) {
+	names, files, err := readPEMFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	var certs []string
+	for i, data := range files {
+		_, err = parseCertPEM(data)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "%s", filepath.Join(dir, names[i]))
+		}
+		certs = append(certs, string(data))
+	}
+	return certs, nil
+}
+
+// readPEMFiles 按文件名顺序读取目录中的全部文件，返回文件名及内容，目录不存在时返回空
+func readPEMFiles(dir string) ([]string, [][]byte, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	var names []string
+	var list [][]byte
+	for _, info := range infos {
+		if !info.Mode().IsRegular() {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(dir, info.Name()))
+		if err != nil {
+			return nil, nil, err
+		}
+		names = append(names, info.Name())
+		list = append(list, data)
+	}
+	return names, list, nil
+}
+
+func parseCertPEM(data []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("证书不是 PEM 格式")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "解析证书失败")
+	}
+	return cert, nil
+}
@@ -0,0 +1,156 @@
+package wallet
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"bewallet/pkg/keystore"
+)
+
+// writeTestMSP 生成由测试 CA 签发的身份，按 MSP 目录结构写入 dir，返回 CA 证书
+func writeTestMSP(t *testing.T, dir string, key *ecdsa.PrivateKey, keyPEM []byte) string {
+	caKey, err := ecdsa.GenerateKey(Curve, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ca.org1.example.com", Organization: []string{"org1.example.com"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTmpl, caTmpl, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "user1", Organization: []string{"org1.example.com"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, caTmpl, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER})
+	for file, data := range map[string][]byte{
+		filepath.Join(MSPSignCerts, "user1-cert.pem"): pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		filepath.Join(MSPKeyStore, "key_sk"):          keyPEM,
+		filepath.Join(MSPCACerts, "ca.pem"):           caPEM,
+	} {
+		path := filepath.Join(dir, file)
+		if err := os.MkdirAll(filepath.Dir(path), keystore.DirPerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, data, keystore.FilePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return string(caPEM)
+}
+
+func TestImportMSP(t *testing.T) {
+	key, err := ecdsa.GenerateKey(Curve, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := x509.EncryptPEMBlock(rand.Reader, "EC PRIVATE KEY", der, []byte("key-password"), x509.PEMCipherAES256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	caPEM := writeTestMSP(t, dir, key, pem.EncodeToMemory(block))
+
+	ks := keystore.NewMemKeyStore()
+	if _, err := ImportMSP(ks, "alice", dir, "", "", []byte("key-password")); err == nil {
+		t.Fatal("缺少 MSP ID 时应导入失败")
+	}
+	if _, err := ImportMSP(ks, "alice", dir, "Org1MSP", "", []byte("wrong")); err == nil {
+		t.Fatal("私钥口令错误时应导入失败")
+	}
+	fw, err := ImportMSP(ks, "alice", dir, "Org1MSP", "", []byte("key-password"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fw.Address() != genAddr(key) {
+		t.Fatalf("导入的地址为 %s", fw.Address())
+	}
+	if fw.OrgMSP != "Org1MSP" || fw.FabMSP.Network != "Org1MSP" || fw.Org != "org1.example.com" {
+		t.Fatalf("MSP 信息不正确: %+v", fw.FabMSP)
+	}
+	if len(fw.CACerts) != 1 || fw.CACerts[0] != caPEM {
+		t.Fatalf("CA 证书不正确: %v", fw.CACerts)
+	}
+	nets, err := LoadFabNet(ks, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, ok := nets["Org1MSP"]; !ok || n.SignCert != fw.SignCert {
+		t.Fatal("导入的身份应保存到账户网络信息中")
+	}
+}
+
+func TestExportMSP(t *testing.T) {
+	key, err := ecdsa.GenerateKey(Curve, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := t.TempDir()
+	writeTestMSP(t, src, key, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	fw, err := ImportMSP(keystore.NewMemKeyStore(), "", src, "Org1MSP", "net", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "msp")
+	if err := fw.ExportMSP(dir, "wrong"); err != keystore.ErrPassword {
+		t.Fatalf("口令错误时应返回 ErrPassword，实际为 %v", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Fatal("口令错误时不应导出")
+	}
+	if err := fw.ExportMSP(dir, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := fw.ExportMSP(dir, ""); err == nil {
+		t.Fatal("目录已存在时应导出失败")
+	}
+	for _, file := range []string{
+		filepath.Join(MSPSignCerts, mspCertFile),
+		filepath.Join(MSPKeyStore, mspKeyFile),
+		filepath.Join(MSPCACerts, "ca.pem"),
+	} {
+		if _, err := os.Stat(filepath.Join(dir, file)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	again, err := ImportMSP(keystore.NewMemKeyStore(), "", dir, "Org1MSP", "net", nil)
+	if err != nil {
+		t.Fatal("导出的目录应能再次导入:", err)
+	}
+	if again.Address() != fw.Address() || again.SignCert != fw.SignCert {
+		t.Fatal("再次导入的身份不一致")
+	}
+}
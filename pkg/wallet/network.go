@@ -0,0 +1,229 @@
+package wallet
+
+import (
+	"encoding/pem"
+	"net"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// Validate 校验节点地址（host:port）及 TLS 根证书（PEM，可包含多个证书）
+func (n *Node) Validate() error {
+	host, port, err := net.SplitHostPort(n.Address)
+	if err != nil {
+		return errors.Wrapf(err, "节点地址 %q 格式错误，应为 host:port", n.Address)
+	}
+	if len(host) == 0 {
+		return errors.Errorf("节点地址 %q 缺少主机名", n.Address)
+	}
+	if p, err := strconv.ParseUint(port, 10, 16); err != nil || p == 0 {
+		return errors.Errorf("节点地址 %q 端口错误", n.Address)
+	}
+	if len(n.TLSCA) == 0 {
+		return nil
+	}
+	rest := []byte(n.TLSCA)
+	for count := 0; ; count++ {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			if count == 0 {
+				return errors.Errorf("节点 %s 的 TLS 根证书不是 PEM 格式", n.Address)
+			}
+			return nil
+		}
+		_, err = parseCertPEM(pem.EncodeToMemory(block))
+		if err != nil {
+			return errors.WithMessagef(err, "节点 %s 的 TLS 根证书", n.Address)
+		}
+	}
+}
+
+// Validate 校验网络名称及全部节点，同一类节点中地址不能重复
+func (n *Network) Validate() error {
+	if len(n.Name) == 0 {
+		return errors.New("缺少网络名称")
+	}
+	for _, group := range []struct {
+		kind  string
+		nodes []*Node
+	}{{"peer", n.Peers}, {"orderer", n.Orderers}} {
+		kind := group.kind
+		seen := make(map[string]bool, len(group.nodes))
+		for _, node := range group.nodes {
+			if node == nil {
+				return errors.Errorf("网络 %s 的 %s 节点为空", n.Name, kind)
+			}
+			err := node.Validate()
+			if err != nil {
+				return err
+			}
+			if seen[node.Address] {
+				return errors.Errorf("网络 %s 的 %s 节点 %s 重复", n.Name, kind, node.Address)
+			}
+			seen[node.Address] = true
+		}
+	}
+	for _, ch := range n.Channels {
+		if len(ch) == 0 {
+			return errors.Errorf("网络 %s 的通道名称为空", n.Name)
+		}
+	}
+	return nil
+}
+
+// mergeNodes 按地址合并节点，地址相同的节点以 nodes 中的为准。
+// 结果为新的切片，不修改 list 的底层数组
+func mergeNodes(list []*Node, nodes []*Node) []*Node {
+	list = append(make([]*Node, 0, len(list)+len(nodes)), list...)
+	for _, node := range nodes {
+		if node == nil {
+			continue
+		}
+		replaced := false
+		for i, n := range list {
+			if n != nil && n.Address == node.Address {
+				list[i] = node
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			list = append(list, node)
+		}
+	}
+	return list
+}
+
+// copyNetwork 深拷贝网络信息
+func copyNetwork(n Network) Network {
+	return Network{
+		Name:     n.Name,
+		Peers:    copyNodes(n.Peers),
+		Orderers: copyNodes(n.Orderers),
+		Channels: append([]string(nil), n.Channels...),
+	}
+}
+
+func copyNodes(nodes []*Node) []*Node {
+	var c []*Node
+	for _, node := range nodes {
+		if node == nil {
+			continue
+		}
+		n := *node
+		c = append(c, &n)
+	}
+	return c
+}
+
+// copyFabNet 深拷贝网络信息及其中的身份
+func copyFabNet(f *FabNet) *FabNet {
+	c := &FabNet{
+		FabMSP:  f.FabMSP,
+		Network: copyNetwork(f.Network),
+	}
+	c.CACerts = append([]string(nil), f.CACerts...)
+	c.IntermediateCerts = append([]string(nil), f.IntermediateCerts...)
+	c.TLSCACerts = append([]string(nil), f.TLSCACerts...)
+	return c
+}
+
+// AddNetwork 为账户添加网络，网络已存在或网络信息校验失败时返回错误
+func (m *Manager) AddNetwork(account string, network Network) error {
+	return m.editNetworks(account, func(nets map[string]*FabNet) error {
+		if err := network.Validate(); err != nil {
+			return err
+		}
+		network = copyNetwork(network)
+		if _, ok := nets[network.Name]; ok {
+			return errors.Errorf("网络 %s 已存在", network.Name)
+		}
+		nets[network.Name] = &FabNet{
+			FabMSP:  FabMSP{Network: network.Name},
+			Network: network,
+		}
+		return nil
+	})
+}
+
+// UpdateNetwork 更新账户已有网络的节点信息，保留网络中的身份
+func (m *Manager) UpdateNetwork(account string, network Network) error {
+	return m.editNetworks(account, func(nets map[string]*FabNet) error {
+		if err := network.Validate(); err != nil {
+			return err
+		}
+		network = copyNetwork(network)
+		fabnet, ok := nets[network.Name]
+		if !ok {
+			return errors.Errorf("网络 %s 不存在", network.Name)
+		}
+		nets[network.Name] = &FabNet{
+			FabMSP:  fabnet.FabMSP,
+			Network: network,
+		}
+		return nil
+	})
+}
+
+// RemoveNetwork 删除账户的网络及其中的身份
+func (m *Manager) RemoveNetwork(account, name string) error {
+	return m.editNetworks(account, func(nets map[string]*FabNet) error {
+		if _, ok := nets[name]; !ok {
+			return errors.Errorf("网络 %s 不存在", name)
+		}
+		delete(nets, name)
+		return nil
+	})
+}
+
+// ListNetworks 按名称排序列出账户的网络信息
+func (m *Manager) ListNetworks(account string) ([]*Network, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	w, ok := m.accounts.find(account)
+	if !ok {
+		return nil, errors.Errorf("账户 %s 不存在", account)
+	}
+	list := make([]*Network, 0, len(m.accounts.networks[w.addr]))
+	for name, fabnet := range m.accounts.networks[w.addr] {
+		n := copyNetwork(fabnet.Network)
+		n.Name = name
+		list = append(list, &n)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list, nil
+}
+
+// editNetworks 在账户网络信息的副本上修改，保存成功后替换 Manager 中的网络信息
+func (m *Manager) editNetworks(account string, edit func(nets map[string]*FabNet) error) error {
+	m.mu.Lock()
+	w, ok := m.accounts.find(account)
+	if !ok {
+		m.mu.Unlock()
+		return errors.Errorf("账户 %s 不存在", account)
+	}
+	nets := make(map[string]*FabNet, len(m.accounts.networks[w.addr]))
+	for k, v := range m.accounts.networks[w.addr] {
+		nets[k] = copyFabNet(v)
+	}
+	err := edit(nets)
+	if err != nil {
+		m.mu.Unlock()
+		return err
+	}
+	err = SaveFabNet(m.ks, w.name, nets)
+	if err != nil {
+		m.mu.Unlock()
+		return err
+	}
+	m.accounts.networks[w.addr] = nets
+	m.mu.Unlock()
+
+	m.notify(Event{Type: EventNetworks, Addr: w.addr, Name: w.name})
+	return nil
+}
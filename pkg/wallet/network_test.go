@@ -0,0 +1,64 @@
+package wallet
+
+import (
+	"testing"
+
+	"bewallet/pkg/keystore"
+)
+
+func TestManagerAddNetwork(t *testing.T) {
+	m, err := NewManager(keystore.NewMemKeyStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Create("alice"); err != nil {
+		t.Fatal(err)
+	}
+
+	err = m.AddNetwork("alice", Network{Name: "test", Peers: []*Node{nil}})
+	if err == nil {
+		t.Fatal("包含空节点的网络应添加失败")
+	}
+	dup := Network{
+		Name:  "test",
+		Peers: []*Node{{Address: "peer0:7051"}, {Address: "peer0:7051"}},
+	}
+	if err := m.AddNetwork("alice", dup); err == nil {
+		t.Fatal("节点地址重复的网络应添加失败")
+	}
+
+	network := Network{
+		Name:     "test",
+		Peers:    []*Node{{Address: "peer0:7051"}},
+		Orderers: []*Node{{Address: "orderer0:7050"}},
+	}
+	if err := m.AddNetwork("alice", network); err != nil {
+		t.Fatal(err)
+	}
+	network.Peers[0].Address = "changed:7051"
+
+	nets := m.GetNetworks("alice")
+	fabnet := nets["test"]
+	if fabnet == nil || len(fabnet.Peers) != 1 || fabnet.Peers[0].Address != "peer0:7051" {
+		t.Fatalf("保存的网络信息不应受调用方修改影响: %+v", fabnet)
+	}
+	fabnet.Peers[0].Address = "changed:7051"
+	fabnet.AddPeers([]*Node{{Address: "peer1:7051"}})
+	again := m.GetNetworks("alice")["test"]
+	if len(again.Peers) != 1 || again.Peers[0].Address != "peer0:7051" {
+		t.Fatalf("GetNetworks 返回值的修改不应影响 Manager: %+v", again.Peers)
+	}
+}
+
+func TestMergeNodesCopies(t *testing.T) {
+	list := make([]*Node, 1, 4)
+	list[0] = &Node{Address: "peer0:7051"}
+	replacement := &Node{Address: "peer0:7051", ServerOverride: "peer0"}
+	merged := mergeNodes(list, []*Node{replacement, {Address: "peer1:7051"}})
+	if len(merged) != 2 || merged[0] != replacement {
+		t.Fatalf("合并结果不正确: %+v", merged)
+	}
+	if list[0].ServerOverride != "" || list[:2][1] != nil {
+		t.Fatal("mergeNodes 不应修改传入切片的底层数组")
+	}
+}
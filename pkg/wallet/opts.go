@@ -0,0 +1,78 @@
+package wallet
+
+type option struct {
+	path       string
+	version    string
+	language   Language
+	words      int
+	passphrase string
+	root       string
+	showOnce   bool
+}
+
+// Option 钱包创建参数
+type Option func(opt *option)
+
+// WithPath 指定密钥派生路径，默认为 DefaultPath
+func WithPath(path string) Option {
+	return func(opt *option) {
+		opt.path = path
+	}
+}
+
+// WithKeyVersion 指定密钥派生版本，默认为 KeyVersionSLIP10；早期版本不使用派生路径，仅用于恢复早期钱包
+func WithKeyVersion(version string) Option {
+	return func(opt *option) {
+		opt.version = version
+	}
+}
+
+// WithLanguage 指定助记词语言，创建钱包时默认为 DefaultLanguage，恢复钱包时默认自动识别
+func WithLanguage(lang Language) Option {
+	return func(opt *option) {
+		opt.language = lang
+	}
+}
+
+// WithWordCount 指定创建钱包时生成的助记词单词数量（12、15、18、21、24），默认为 DefaultWordCount
+func WithWordCount(words int) Option {
+	return func(opt *option) {
+		opt.words = words
+	}
+}
+
+// WithPassphrase 指定助记词口令（BIP-39 passphrase），口令不会被保存
+func WithPassphrase(passphrase string) Option {
+	return func(opt *option) {
+		opt.passphrase = passphrase
+	}
+}
+
+// WithShowOnce 不保存助记词，助记词仅能在创建后通过 RevealMnemonic 展示一次
+func WithShowOnce() Option {
+	return func(opt *option) {
+		opt.showOnce = true
+	}
+}
+
+// withRoot 要求派生主密钥与已有钱包一致，用于校验助记词口令
+func withRoot(root string) Option {
+	return func(opt *option) {
+		opt.root = root
+	}
+}
+
+func newOption(opts ...Option) *option {
+	opt := &option{
+		path:    DefaultPath,
+		version: KeyVersionSLIP10,
+		words:   DefaultWordCount,
+	}
+	for _, o := range opts {
+		o(opt)
+	}
+	if opt.version != KeyVersionSLIP10 {
+		opt.path = ""
+	}
+	return opt
+}
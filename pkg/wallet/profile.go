@@ -0,0 +1,365 @@
+package wallet
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// 连接配置中节点地址的协议前缀
+const (
+	profileSchemeTLS   = "grpcs://"
+	profileSchemePlain = "grpc://"
+
+	grpcOptSSLTargetNameOverride = "ssl-target-name-override"
+	grpcOptHostnameOverride      = "hostnameOverride"
+)
+
+// ConnectionProfile Hyperledger Fabric 通用连接配置（common connection profile），
+// 仅包含导入导出网络节点所需的字段
+type ConnectionProfile struct {
+	Name          string                          `json:"name" yaml:"name"`
+	Version       string                          `json:"version,omitempty" yaml:"version,omitempty"`
+	Client        *ProfileClient                  `json:"client,omitempty" yaml:"client,omitempty"`
+	Channels      map[string]*ProfileChannel      `json:"channels,omitempty" yaml:"channels,omitempty"`
+	Organizations map[string]*ProfileOrganization `json:"organizations,omitempty" yaml:"organizations,omitempty"`
+	Orderers      map[string]*ProfileNode         `json:"orderers,omitempty" yaml:"orderers,omitempty"`
+	Peers         map[string]*ProfileNode         `json:"peers,omitempty" yaml:"peers,omitempty"`
+}
+
+// ProfileClient 连接配置中的客户端信息
+type ProfileClient struct {
+	Organization string `json:"organization,omitempty" yaml:"organization,omitempty"`
+}
+
+// ProfileChannel 连接配置中的通道，Peers 的值为节点角色等选项
+type ProfileChannel struct {
+	Orderers []string               `json:"orderers,omitempty" yaml:"orderers,omitempty"`
+	Peers    map[string]interface{} `json:"peers,omitempty" yaml:"peers,omitempty"`
+}
+
+// ProfileOrganization 连接配置中的组织
+type ProfileOrganization struct {
+	MSPID                  string   `json:"mspid" yaml:"mspid"`
+	Peers                  []string `json:"peers,omitempty" yaml:"peers,omitempty"`
+	CertificateAuthorities []string `json:"certificateAuthorities,omitempty" yaml:"certificateAuthorities,omitempty"`
+}
+
+// ProfileNode 连接配置中的 peer 或 orderer 节点
+type ProfileNode struct {
+	URL         string                 `json:"url" yaml:"url"`
+	TLSCACerts  *ProfileTLSCACerts     `json:"tlsCACerts,omitempty" yaml:"tlsCACerts,omitempty"`
+	GRPCOptions map[string]interface{} `json:"grpcOptions,omitempty" yaml:"grpcOptions,omitempty"`
+}
+
+// ProfileTLSCACerts 节点 TLS 根证书，Pem 为内联证书，Path 为证书文件路径
+type ProfileTLSCACerts struct {
+	Pem  string `json:"pem,omitempty" yaml:"pem,omitempty"`
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+}
+
+// LoadConnectionProfile 读取 YAML 或 JSON 格式的连接配置文件，证书相对路径相对于配置文件所在目录
+func LoadConnectionProfile(file string) (*ConnectionProfile, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return ParseConnectionProfile(data, filepath.Dir(file))
+}
+
+// ParseConnectionProfile 解析 YAML 或 JSON 格式的连接配置，并读取 tlsCACerts.path 指向的证书，
+// 证书相对路径相对于 dir
+func ParseConnectionProfile(data []byte, dir string) (*ConnectionProfile, error) {
+	cp := &ConnectionProfile{}
+	var err error
+	if trimmed := bytes.TrimSpace(data); len(trimmed) != 0 && trimmed[0] == '{' {
+		err = json.Unmarshal(data, cp)
+	} else {
+		err = yaml.Unmarshal(data, cp)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "解析连接配置失败")
+	}
+	for kind, nodes := range map[string]map[string]*ProfileNode{"peer": cp.Peers, "orderer": cp.Orderers} {
+		for name, node := range nodes {
+			if node == nil || node.TLSCACerts == nil || len(node.TLSCACerts.Pem) != 0 || len(node.TLSCACerts.Path) == 0 {
+				continue
+			}
+			path := node.TLSCACerts.Path
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(dir, path)
+			}
+			pem, err := ioutil.ReadFile(path)
+			if err != nil {
+				return nil, errors.WithMessagef(err, "读取 %s %s 的 TLS 根证书失败", kind, name)
+			}
+			node.TLSCACerts.Pem = string(pem)
+		}
+	}
+	return cp, nil
+}
+
+// FabNet 由连接配置生成网络信息，network 为空时使用连接配置名称。
+// channel 不为空时仅包含该通道的节点，否则包含客户端所在组织的 peer（未指定组织时为全部 peer）及全部 orderer
+func (cp *ConnectionProfile) FabNet(network, channel string) (*FabNet, error) {
+	if len(network) == 0 {
+		network = cp.Name
+	}
+	var peers, orderers []string
+	var org *ProfileOrganization
+	var orgName string
+	if cp.Client != nil && len(cp.Client.Organization) != 0 {
+		orgName = cp.Client.Organization
+		org = cp.Organizations[orgName]
+		if org == nil {
+			return nil, errors.Errorf("连接配置中没有客户端所在组织 %s", orgName)
+		}
+	}
+	switch {
+	case len(channel) != 0:
+		ch, ok := cp.Channels[channel]
+		if !ok || ch == nil {
+			return nil, errors.Errorf("连接配置中没有通道 %s", channel)
+		}
+		for name := range ch.Peers {
+			peers = append(peers, name)
+		}
+		orderers = ch.Orderers
+	case org != nil:
+		peers = org.Peers
+	default:
+		for name := range cp.Peers {
+			peers = append(peers, name)
+		}
+	}
+	if len(channel) == 0 || len(orderers) == 0 {
+		orderers = nil
+		for name := range cp.Orderers {
+			orderers = append(orderers, name)
+		}
+	}
+	sort.Strings(peers)
+	sort.Strings(orderers)
+
+	fabnet := &FabNet{
+		FabMSP:  FabMSP{Network: network},
+		Network: Network{Name: network, Channels: cp.channels(channel, peers)},
+	}
+	if org != nil {
+		fabnet.OrgMSP = org.MSPID
+		fabnet.Org = orgName
+	}
+	for _, name := range peers {
+		node, err := profileNode(cp.Peers, "peer", name)
+		if err != nil {
+			return nil, err
+		}
+		fabnet.Peers = append(fabnet.Peers, node)
+	}
+	for _, name := range orderers {
+		node, err := profileNode(cp.Orderers, "orderer", name)
+		if err != nil {
+			return nil, err
+		}
+		fabnet.Orderers = append(fabnet.Orderers, node)
+	}
+	err := fabnet.Network.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return fabnet, nil
+}
+
+// channels 返回导入的通道：指定 channel 时仅为该通道，否则为包含 peers 中任一节点的通道
+func (cp *ConnectionProfile) channels(channel string, peers []string) []string {
+	if len(channel) != 0 {
+		return []string{channel}
+	}
+	imported := make(map[string]bool, len(peers))
+	for _, p := range peers {
+		imported[p] = true
+	}
+	var list []string
+	for name, ch := range cp.Channels {
+		if ch == nil {
+			continue
+		}
+		for p := range ch.Peers {
+			if imported[p] {
+				list = append(list, name)
+				break
+			}
+		}
+	}
+	sort.Strings(list)
+	return list
+}
+
+// profileNode 连接配置节点转换为网络节点。钱包以是否有 TLS 根证书区分是否使用 TLS，
+// 因此 grpcs:// 节点必须提供 tlsCACerts，grpc:// 节点忽略 tlsCACerts；无协议前缀时有 tlsCACerts 即使用 TLS
+func profileNode(nodes map[string]*ProfileNode, kind, name string) (*Node, error) {
+	pn, ok := nodes[name]
+	if !ok || pn == nil {
+		return nil, errors.Errorf("连接配置中没有 %s %s", kind, name)
+	}
+	if len(pn.URL) == 0 {
+		return nil, errors.Errorf("连接配置中 %s %s 缺少 url", kind, name)
+	}
+	var tlsCA string
+	if pn.TLSCACerts != nil {
+		tlsCA = pn.TLSCACerts.Pem
+	}
+	addr := pn.URL
+	switch {
+	case strings.HasPrefix(addr, profileSchemeTLS):
+		addr = strings.TrimPrefix(addr, profileSchemeTLS)
+		if len(tlsCA) == 0 {
+			return nil, errors.Errorf("连接配置中 %s %s 使用 TLS，但缺少 tlsCACerts", kind, name)
+		}
+	case strings.HasPrefix(addr, profileSchemePlain):
+		addr = strings.TrimPrefix(addr, profileSchemePlain)
+		tlsCA = ""
+	}
+	node := &Node{Address: addr, TLSCA: tlsCA}
+	for _, opt := range []string{grpcOptSSLTargetNameOverride, grpcOptHostnameOverride} {
+		if v, ok := pn.GRPCOptions[opt].(string); ok && len(v) != 0 {
+			node.ServerOverride = v
+			break
+		}
+	}
+	return node, nil
+}
+
+// ConnectionProfile 将网络信息导出为连接配置，节点以 ServerOverride 或主机名命名，TLS 根证书内联，
+// 网络中的每个通道包含全部 peer 及 orderer 节点
+func (fw *FabNet) ConnectionProfile() *ConnectionProfile {
+	name := fw.Network.Name
+	if len(name) == 0 {
+		name = fw.FabMSP.Network
+	}
+	cp := &ConnectionProfile{
+		Name:     name,
+		Version:  "1.0.0",
+		Peers:    make(map[string]*ProfileNode),
+		Orderers: make(map[string]*ProfileNode),
+	}
+	var peers []string
+	for _, node := range fw.Peers {
+		n := exportNodeName(cp.Peers, node)
+		cp.Peers[n] = exportNode(node)
+		peers = append(peers, n)
+	}
+	var orderers []string
+	for _, node := range fw.Orderers {
+		n := exportNodeName(cp.Orderers, node)
+		cp.Orderers[n] = exportNode(node)
+		orderers = append(orderers, n)
+	}
+	if len(fw.Channels) != 0 {
+		cp.Channels = make(map[string]*ProfileChannel, len(fw.Channels))
+		for _, ch := range fw.Channels {
+			pc := &ProfileChannel{
+				Orderers: orderers,
+				Peers:    make(map[string]interface{}, len(peers)),
+			}
+			for _, p := range peers {
+				pc.Peers[p] = map[string]interface{}{}
+			}
+			cp.Channels[ch] = pc
+		}
+	}
+	if len(fw.OrgMSP) != 0 {
+		org := fw.Org
+		if len(org) == 0 {
+			org = fw.OrgMSP
+		}
+		cp.Client = &ProfileClient{Organization: org}
+		cp.Organizations = map[string]*ProfileOrganization{
+			org: {MSPID: fw.OrgMSP, Peers: peers},
+		}
+	}
+	return cp
+}
+
+// exportNodeName 导出节点名称，名称重复时附加端口
+func exportNodeName(nodes map[string]*ProfileNode, node *Node) string {
+	host, port, _ := net.SplitHostPort(node.Address)
+	name := node.ServerOverride
+	if len(name) == 0 {
+		name = host
+	}
+	if _, ok := nodes[name]; ok {
+		name = fmt.Sprintf("%s-%s", name, port)
+	}
+	return name
+}
+
+func exportNode(node *Node) *ProfileNode {
+	pn := &ProfileNode{URL: profileSchemePlain + node.Address}
+	if len(node.TLSCA) != 0 {
+		pn.URL = profileSchemeTLS + node.Address
+		pn.TLSCACerts = &ProfileTLSCACerts{Pem: node.TLSCA}
+	}
+	if len(node.ServerOverride) != 0 {
+		pn.GRPCOptions = map[string]interface{}{
+			grpcOptSSLTargetNameOverride: node.ServerOverride,
+			grpcOptHostnameOverride:      node.ServerOverride,
+		}
+	}
+	return pn
+}
+
+// Marshal 编码连接配置，asJSON 为 false 时为 YAML
+func (cp *ConnectionProfile) Marshal(asJSON bool) ([]byte, error) {
+	if asJSON {
+		return json.MarshalIndent(cp, "", "  ")
+	}
+	return yaml.Marshal(cp)
+}
+
+// ImportProfile 将连接配置导入为账户的网络，网络已存在时更新其节点，并在网络尚无身份时记录连接配置中的 MSP ID。
+// network 为空时使用连接配置名称，channel 的含义见 ConnectionProfile.FabNet
+func (m *Manager) ImportProfile(account string, cp *ConnectionProfile, network, channel string) (*FabNet, error) {
+	fabnet, err := cp.FabNet(network, channel)
+	if err != nil {
+		return nil, err
+	}
+	err = m.editNetworks(account, func(nets map[string]*FabNet) error {
+		if old, ok := nets[fabnet.Network.Name]; ok {
+			fm := old.FabMSP
+			if len(fm.SignCert) == 0 && len(fabnet.OrgMSP) != 0 {
+				fm.OrgMSP, fm.Org = fabnet.OrgMSP, fabnet.Org
+			}
+			fabnet.FabMSP = fm
+		}
+		nets[fabnet.Network.Name] = fabnet
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fabnet, nil
+}
+
+// ExportProfile 将账户的网络导出为连接配置
+func (m *Manager) ExportProfile(account, network string) (*ConnectionProfile, error) {
+	nets := m.GetNetworks(account)
+	if nets == nil {
+		return nil, errors.Errorf("账户 %s 不存在", account)
+	}
+	fabnet, ok := nets[network]
+	if !ok {
+		return nil, errors.Errorf("账户没有网络 %s", network)
+	}
+	cp := fabnet.ConnectionProfile()
+	cp.Name = network
+	return cp, nil
+}
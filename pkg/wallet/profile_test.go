@@ -0,0 +1,216 @@
+package wallet
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"bewallet/pkg/keystore"
+)
+
+// testTLSCA 生成用作节点 TLS 根证书的 PEM 证书
+func testTLSCA(t *testing.T) string {
+	w, err := CreateWallet(keystore.NewMemKeyStore(), "tlsca")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, err := w.NewDevCA("dev", "Org1MSP", &DevCAOpts{Org: "org1.example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ca.CertPEM()
+}
+
+func indent(s, prefix string) string {
+	return prefix + strings.ReplaceAll(strings.TrimSpace(s), "\n", "\n"+prefix)
+}
+
+const testProfileYAML = `
+name: test-network
+version: 1.0.0
+client:
+  organization: Org1
+channels:
+  mychannel:
+    orderers:
+      - orderer.example.com
+    peers:
+      peer0.org1.example.com:
+        endorsingPeer: true
+  otherchannel:
+    peers:
+      peer0.org2.example.com: {}
+organizations:
+  Org1:
+    mspid: Org1MSP
+    peers:
+      - peer0.org1.example.com
+orderers:
+  orderer.example.com:
+    url: grpc://localhost:7050
+peers:
+  peer0.org1.example.com:
+    url: grpcs://localhost:7051
+    tlsCACerts:
+      pem: |
+%s
+    grpcOptions:
+      ssl-target-name-override: peer0.org1.example.com
+  peer0.org2.example.com:
+    url: grpcs://localhost:9051
+    tlsCACerts:
+      pem: |
+%s
+`
+
+func TestParseConnectionProfileYAML(t *testing.T) {
+	tlsCA := testTLSCA(t)
+	pem := indent(tlsCA, "        ")
+	data := []byte(strings.Replace(strings.Replace(testProfileYAML, "%s", pem, 1), "%s", pem, 1))
+	cp, err := ParseConnectionProfile(data, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	fabnet, err := cp.FabNet("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fabnet.Network.Name != "test-network" || fabnet.OrgMSP != "Org1MSP" || fabnet.Org != "Org1" {
+		t.Fatalf("网络信息不正确: %+v", fabnet)
+	}
+	if len(fabnet.Peers) != 1 || fabnet.Peers[0].Address != "localhost:7051" ||
+		fabnet.Peers[0].ServerOverride != "peer0.org1.example.com" || len(fabnet.Peers[0].TLSCA) == 0 {
+		t.Fatalf("peer 节点不正确: %+v", fabnet.Peers)
+	}
+	if len(fabnet.Orderers) != 1 || fabnet.Orderers[0].Address != "localhost:7050" || len(fabnet.Orderers[0].TLSCA) != 0 {
+		t.Fatalf("orderer 节点不正确: %+v", fabnet.Orderers)
+	}
+	if !reflect.DeepEqual(fabnet.Channels, []string{"mychannel"}) {
+		t.Fatalf("通道应为 mychannel，实际为 %v", fabnet.Channels)
+	}
+
+	ch, err := cp.FabNet("net", "otherchannel")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ch.Network.Name != "net" || len(ch.Peers) != 1 || ch.Peers[0].Address != "localhost:9051" {
+		t.Fatalf("按通道导入的网络不正确: %+v", ch.Network)
+	}
+	if _, err := cp.FabNet("", "missing"); err == nil {
+		t.Fatal("通道不存在时应返回错误")
+	}
+}
+
+func TestParseConnectionProfileJSON(t *testing.T) {
+	tlsCA := testTLSCA(t)
+	profile := map[string]interface{}{
+		"name": "json-network",
+		"peers": map[string]interface{}{
+			"peer0": map[string]interface{}{
+				"url":        "grpcs://peer0:7051",
+				"tlsCACerts": map[string]string{"pem": tlsCA},
+			},
+			"peer1": map[string]interface{}{
+				"url":        "grpc://peer1:7051",
+				"tlsCACerts": map[string]string{"pem": tlsCA},
+			},
+		},
+	}
+	data, err := json.Marshal(profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cp, err := ParseConnectionProfile(data, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	fabnet, err := cp.FabNet("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fabnet.Peers) != 2 || len(fabnet.Peers[0].TLSCA) == 0 || len(fabnet.Peers[1].TLSCA) != 0 {
+		t.Fatalf("grpcs 节点应保留 TLS 根证书，grpc 节点应忽略: %+v", fabnet.Peers)
+	}
+
+	delete(profile["peers"].(map[string]interface{})["peer0"].(map[string]interface{}), "tlsCACerts")
+	data, err = json.Marshal(profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cp, err = ParseConnectionProfile(data, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cp.FabNet("", ""); err == nil {
+		t.Fatal("grpcs 节点缺少 tlsCACerts 时应返回错误")
+	}
+}
+
+func TestConnectionProfileRoundTrip(t *testing.T) {
+	tlsCA := testTLSCA(t)
+	m, err := NewManager(keystore.NewMemKeyStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Create("alice"); err != nil {
+		t.Fatal(err)
+	}
+	network := Network{
+		Name: "test",
+		Peers: []*Node{
+			{Address: "localhost:7051", ServerOverride: "peer0.org1.example.com", TLSCA: tlsCA},
+			{Address: "localhost:8051"},
+		},
+		Orderers: []*Node{{Address: "localhost:7050", TLSCA: tlsCA}},
+		Channels: []string{"mychannel"},
+	}
+	if err := m.AddNetwork("alice", network); err != nil {
+		t.Fatal(err)
+	}
+	cp, err := m.ExportProfile("alice", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch := cp.Channels["mychannel"]
+	if ch == nil || len(ch.Peers) != 2 || len(ch.Orderers) != 1 {
+		t.Fatalf("导出的通道不正确: %+v", cp.Channels)
+	}
+
+	for _, asJSON := range []bool{false, true} {
+		data, err := cp.Marshal(asJSON)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !asJSON && !strings.Contains(string(data), "grpc://localhost:8051") {
+			t.Fatalf("非 TLS 节点应导出为 grpc://:\n%s", data)
+		}
+		parsed, err := ParseConnectionProfile(data, t.TempDir())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := m.ImportProfile("alice", parsed, "copy", ""); err != nil {
+			t.Fatal(err)
+		}
+		list, err := m.ListNetworks("alice")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got *Network
+		for _, n := range list {
+			if n.Name == "copy" {
+				got = n
+			}
+		}
+		want := copyNetwork(network)
+		want.Name = "copy"
+		// 连接配置中的节点按名称排序
+		want.Peers[0], want.Peers[1] = want.Peers[1], want.Peers[0]
+		if !reflect.DeepEqual(got, &want) {
+			t.Fatalf("json=%v 导入导出后网络不一致:\n%+v\n%+v", asJSON, got, &want)
+		}
+		if err := m.RemoveNetwork("alice", "copy"); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
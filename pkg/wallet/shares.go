@@ -0,0 +1,256 @@
+package wallet
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tyler-smith/go-bip39"
+
+	"bewallet/pkg/keystore"
+	"bewallet/pkg/utils"
+)
+
+// 助记词份额：对 digest(4) | BIP-39 熵 做 Shamir 秘密共享（见 utils.ShamirSplit），每份份额编码为
+//
+//	SharePrefix + hex(version(1) | id(2) | threshold(1) | x(1) | flags(1) | language(1) | keyVersion(1) |
+//	                  len(path)(1) | path | y | checksum(4))
+//
+// id 为同一次拆分的随机标识；digest 为熵的 SHA-256 前 4 字节，与熵一同共享（类似 SLIP-39 的 digest share），
+// 仅在集齐 threshold 份份额后才能得到，用于确认还原结果；
+// checksum 为之前全部字节的 SHA-256 前 4 字节，用于发现抄写错误的份额。
+// 助记词口令不包含在份额中，钱包使用了口令时恢复需另行提供。
+const (
+	SharePrefix  = "bwss"
+	ShareVersion = 2
+
+	shareFlagPassphrase = 1
+	shareChecksumLen    = 4
+	shareDigestLen      = 4
+)
+
+// keyVersions 份额中记录的密钥派生版本编号
+var keyVersions = []string{
+	KeyVersionSLIP10,
+	KeyVersionLegacy,
+	KeyVersionLegacyGo120,
+	KeyVersionLegacyGo120Shift,
+}
+
+// 错误
+var (
+	ErrShareChecksum = errors.New("份额校验失败，请检查是否抄写错误")
+	ErrShareMismatch = errors.New("份额不属于同一次拆分")
+)
+
+// Share 助记词份额
+type Share struct {
+	ID         uint16   // 同一次拆分的份额 ID 相同
+	Threshold  int      // 还原所需份额数
+	Index      int      // 份额序号，从 1 开始
+	Language   Language // 助记词语言
+	KeyVersion string   // 密钥派生版本
+	Path       string   // 密钥派生路径
+	Passphrase bool     // 钱包是否使用了助记词口令
+
+	value []byte
+}
+
+// SplitMnemonic 校验密钥存储口令后将钱包助记词拆分为 shares 份份额，任意 threshold 份可恢复钱包
+func (w *Wallet) SplitMnemonic(password string, shares, threshold int) ([]string, error) {
+	err := w.verifyPassword(password, "拆分助记词")
+	if err != nil {
+		return nil, err
+	}
+	if !w.Recoverable() {
+		return nil, errors.New("钱包无法由助记词恢复，不能拆分助记词")
+	}
+	if len(w.mnemonic) == 0 {
+		return nil, ErrMnemonicUnavailable
+	}
+	entropy, lang, err := mnemonicToEntropy(w.mnemonic, w.language)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(entropy)
+	secret := append(sum[:shareDigestLen:shareDigestLen], entropy...)
+	values, err := utils.ShamirSplit(secret, shares, threshold)
+	if err != nil {
+		return nil, err
+	}
+	id := make([]byte, 2)
+	if _, err := io.ReadFull(rand.Reader, id); err != nil {
+		return nil, err
+	}
+	out := make([]string, 0, shares)
+	for _, v := range values {
+		s := &Share{
+			ID:         binary.BigEndian.Uint16(id),
+			Threshold:  threshold,
+			Index:      int(v[0]),
+			Language:   lang,
+			KeyVersion: w.version,
+			Path:       w.path,
+			Passphrase: w.passphrase,
+			value:      v[1:],
+		}
+		str, err := s.encode()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, str)
+	}
+	return out, nil
+}
+
+// ParseShare 解析并校验份额
+func ParseShare(share string) (*Share, error) {
+	share = strings.TrimSpace(share)
+	if !strings.HasPrefix(share, SharePrefix) {
+		return nil, errors.Errorf("份额应以 %s 开头", SharePrefix)
+	}
+	data, err := hex.DecodeString(strings.TrimPrefix(share, SharePrefix))
+	if err != nil {
+		return nil, ErrShareChecksum
+	}
+	if len(data) < 9+shareDigestLen+1+shareChecksumLen {
+		return nil, ErrShareChecksum
+	}
+	body, checksum := data[:len(data)-shareChecksumLen], data[len(data)-shareChecksumLen:]
+	sum := sha256.Sum256(body)
+	if !bytes.Equal(sum[:shareChecksumLen], checksum) {
+		return nil, ErrShareChecksum
+	}
+	if body[0] != ShareVersion {
+		return nil, errors.Errorf("不支持的份额版本 %d", body[0])
+	}
+	lang, ver, pathLen := int(body[6]), int(body[7]), int(body[8])
+	if lang >= len(languages) || ver >= len(keyVersions) || len(body) < 9+pathLen+shareDigestLen+1 {
+		return nil, ErrShareChecksum
+	}
+	return &Share{
+		ID:         binary.BigEndian.Uint16(body[1:3]),
+		Threshold:  int(body[3]),
+		Index:      int(body[4]),
+		Passphrase: body[5]&shareFlagPassphrase != 0,
+		Language:   languages[lang],
+		KeyVersion: keyVersions[ver],
+		Path:       string(body[9 : 9+pathLen]),
+		value:      body[9+pathLen:],
+	}, nil
+}
+
+func (s *Share) encode() (string, error) {
+	lang, ver := -1, -1
+	for i, l := range languages {
+		if l == s.Language {
+			lang = i
+		}
+	}
+	for i, v := range keyVersions {
+		if v == s.KeyVersion {
+			ver = i
+		}
+	}
+	if lang < 0 || ver < 0 || len(s.Path) > 255 {
+		return "", errors.New("钱包派生信息无法编码到份额中")
+	}
+	var flags byte
+	if s.Passphrase {
+		flags |= shareFlagPassphrase
+	}
+	buf := []byte{ShareVersion, byte(s.ID >> 8), byte(s.ID), byte(s.Threshold), byte(s.Index), flags, byte(lang), byte(ver), byte(len(s.Path))}
+	buf = append(buf, s.Path...)
+	buf = append(buf, s.value...)
+	sum := sha256.Sum256(buf)
+	buf = append(buf, sum[:shareChecksumLen]...)
+	return SharePrefix + hex.EncodeToString(buf), nil
+}
+
+// CombineShares 由份额还原助记词，返回的 Share 记录了恢复钱包所需的派生信息
+func CombineShares(shares []string) (string, *Share, error) {
+	if len(shares) == 0 {
+		return "", nil, errors.New("缺少份额")
+	}
+	parsed := make([]*Share, 0, len(shares))
+	values := make([][]byte, 0, len(shares))
+	for i, str := range shares {
+		s, err := ParseShare(str)
+		if err != nil {
+			return "", nil, errors.WithMessagef(err, "第 %d 份份额无效", i+1)
+		}
+		if len(parsed) != 0 && !s.sameSplit(parsed[0]) {
+			return "", nil, errors.WithMessagef(ErrShareMismatch, "第 %d 份份额", i+1)
+		}
+		parsed = append(parsed, s)
+		values = append(values, append([]byte{byte(s.Index)}, s.value...))
+	}
+	first := parsed[0]
+	if len(parsed) < first.Threshold {
+		return "", nil, errors.Errorf("份额数量不足，需要 %d 份，仅提供了 %d 份", first.Threshold, len(parsed))
+	}
+	secret, err := utils.ShamirCombine(values)
+	if err != nil {
+		return "", nil, err
+	}
+	want, entropy := secret[:shareDigestLen], secret[shareDigestLen:]
+	sum := sha256.Sum256(entropy)
+	if !bytes.Equal(sum[:shareDigestLen], want) {
+		return "", nil, errors.New("还原结果校验失败，份额可能已损坏或重复")
+	}
+	mnemonic, err := entropyToMnemonic(entropy, first.Language)
+	if err != nil {
+		return "", nil, err
+	}
+	return mnemonic, first, nil
+}
+
+func (s *Share) sameSplit(o *Share) bool {
+	return s.ID == o.ID && s.Threshold == o.Threshold && s.Language == o.Language &&
+		s.KeyVersion == o.KeyVersion && s.Path == o.Path && s.Passphrase == o.Passphrase &&
+		len(s.value) == len(o.value)
+}
+
+// RecoverWalletFromShares 由份额恢复钱包，钱包使用了助记词口令时需通过 WithPassphrase 提供
+func RecoverWalletFromShares(ks keystore.KeyStore, name string, shares []string, opts ...Option) (*Wallet, error) {
+	mnemonic, s, err := CombineShares(shares)
+	if err != nil {
+		return nil, err
+	}
+	if s.Passphrase && len(newOption(opts...).passphrase) == 0 {
+		return nil, errors.New("钱包使用了助记词口令，恢复时需要提供")
+	}
+	opts = append([]Option{WithLanguage(s.Language), WithKeyVersion(s.KeyVersion), WithPath(s.Path)}, opts...)
+	return RecoverWallet(ks, name, mnemonic, opts...)
+}
+
+func mnemonicToEntropy(mnemonic string, lang Language) ([]byte, Language, error) {
+	lang, err := checkMnemonic(mnemonic, lang)
+	if err != nil {
+		return nil, "", err
+	}
+	bip39Lock.Lock()
+	defer bip39Lock.Unlock()
+	bip39.SetWordList(wordLists[lang])
+	entropy, err := bip39.EntropyFromMnemonic(mnemonic)
+	if err != nil {
+		return nil, "", err
+	}
+	return entropy, lang, nil
+}
+
+func entropyToMnemonic(entropy []byte, lang Language) (string, error) {
+	bip39Lock.Lock()
+	defer bip39Lock.Unlock()
+	bip39.SetWordList(wordLists[lang])
+	mnemonic, err := bip39.NewMnemonic(entropy)
+	if err != nil {
+		return "", err
+	}
+	return normalizeMnemonic(mnemonic), nil
+}
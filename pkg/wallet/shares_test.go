@@ -0,0 +1,91 @@
+package wallet
+
+import (
+	"strings"
+	"testing"
+
+	"bewallet/pkg/keystore"
+)
+
+func TestSplitAndCombineShares(t *testing.T) {
+	ks := keystore.NewMemKeyStore()
+	w, err := CreateWallet(ks, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mnemonic, err := w.RevealMnemonic("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	shares, err := w.SplitMnemonic("", 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shares) != 5 {
+		t.Fatalf("应生成 5 份份额，实际 %d 份", len(shares))
+	}
+
+	got, s, err := CombineShares([]string{shares[4], shares[0], shares[2]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != mnemonic {
+		t.Fatal("还原的助记词与原助记词不一致")
+	}
+	if s.Path != w.Path() || s.KeyVersion != w.version {
+		t.Fatalf("份额中的派生信息不正确: %+v", s)
+	}
+
+	if _, _, err := CombineShares(shares[:2]); err == nil {
+		t.Fatal("份额数量不足时应返回错误")
+	}
+	if _, _, err := CombineShares([]string{shares[0], shares[1], shares[1]}); err == nil {
+		t.Fatal("重复的份额应返回错误")
+	}
+
+	bad := []byte(shares[3])
+	i := len(SharePrefix) + 20
+	if bad[i] == '0' {
+		bad[i] = '1'
+	} else {
+		bad[i] = '0'
+	}
+	if _, err := ParseShare(string(bad)); err != ErrShareChecksum {
+		t.Fatalf("抄写错误的份额应返回 ErrShareChecksum，实际为 %v", err)
+	}
+
+	r, err := RecoverWalletFromShares(keystore.NewMemKeyStore(), "bob", []string{shares[1], shares[3], shares[4]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Address() != w.Address() {
+		t.Fatalf("恢复的地址 %s 与原地址 %s 不一致", r.Address(), w.Address())
+	}
+}
+
+func TestSharesHideDigest(t *testing.T) {
+	w, err := CreateWallet(keystore.NewMemKeyStore(), "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	entropy, _, err := mnemonicToEntropy(w.mnemonic, w.language)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shares, err := w.SplitMnemonic("", 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, str := range shares {
+		s, err := ParseShare(str)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(s.value) != shareDigestLen+len(entropy) {
+			t.Fatalf("份额值长度应为 %d，实际为 %d", shareDigestLen+len(entropy), len(s.value))
+		}
+		if !strings.HasPrefix(str, SharePrefix) {
+			t.Fatalf("份额前缀不正确: %s", str)
+		}
+	}
+}
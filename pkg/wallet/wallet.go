@@ -1,7 +1,6 @@
 package wallet
 
 import (
-	"bytes"
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
@@ -12,8 +11,8 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
 	"github.com/tyler-smith/go-bip39"
-	"github.com/tyler-smith/go-bip39/wordlists"
 
 	"bewallet/pkg/keystore"
 	"bewallet/pkg/utils"
@@ -24,19 +23,35 @@ var (
 	Curve = elliptic.P256()
 )
 
+// 错误
+var (
+	ErrMnemonicUnavailable = errors.New("助记词未保存或已展示过")
+)
+
 // Secret ..
 type Secret struct {
-	Key      []byte
-	Mnemonic string
+	Key        []byte
+	Mnemonic   string
+	Version    string `json:",omitempty"` // 密钥派生版本，为空表示尚未迁移的早期数据
+	Path       string `json:",omitempty"` // 密钥派生路径，早期版本为空
+	Root       string `json:",omitempty"` // 派生主密钥对应的地址，用于识别同一助记词派生的账户
+	Language   string `json:",omitempty"` // 助记词语言
+	Passphrase bool   `json:",omitempty"` // 是否使用了助记词口令，口令本身不保存
 }
 
 // Wallet .
 type Wallet struct {
 	keystore.KeyStore
-	private  *ecdsa.PrivateKey
-	mnemonic string
-	addr     string
-	name     string
+	private    *ecdsa.PrivateKey
+	mnemonic   string
+	version    string
+	path       string
+	root       string
+	language   Language
+	passphrase bool // 是否使用了助记词口令
+	showOnce   bool // 助记词不保存，仅在创建后展示一次
+	addr       string
+	name       string
 }
 
 // Sign 私钥签名 (fabric 签名)
@@ -78,9 +93,18 @@ func (w *Wallet) store() error {
 	if err != nil {
 		return err
 	}
+	mnemonic := w.mnemonic
+	if w.showOnce {
+		mnemonic = ""
+	}
 	sec := Secret{
-		Key:      priRaw,
-		Mnemonic: w.mnemonic,
+		Key:        priRaw,
+		Mnemonic:   mnemonic,
+		Version:    w.version,
+		Path:       w.path,
+		Root:       w.root,
+		Language:   string(w.language),
+		Passphrase: w.passphrase,
 	}
 	secRaw, err := json.Marshal(sec)
 	if err != nil {
@@ -93,9 +117,30 @@ func (w *Wallet) store() error {
 	return w.Store(opt)
 }
 
-// ShowMnemonic 展示助记词
-func (w *Wallet) ShowMnemonic() string {
-	return w.mnemonic
+// RevealMnemonic 校验密钥存储口令后返回助记词。
+// 使用 WithShowOnce 创建的钱包仅能在创建后展示一次，之后返回 ErrMnemonicUnavailable
+func (w *Wallet) RevealMnemonic(password string) (string, error) {
+	err := w.verifyPassword(password, "展示助记词")
+	if err != nil {
+		return "", err
+	}
+	if len(w.mnemonic) == 0 {
+		return "", ErrMnemonicUnavailable
+	}
+	mnemonic := w.mnemonic
+	if w.showOnce {
+		w.mnemonic = ""
+	}
+	return mnemonic, nil
+}
+
+// verifyPassword 导出敏感信息前校验密钥存储口令，action 用于错误提示
+func (w *Wallet) verifyPassword(password, action string) error {
+	auth, ok := w.KeyStore.(keystore.Authenticator)
+	if !ok {
+		return errors.Errorf("密钥存储不支持口令校验，无法%s", action)
+	}
+	return auth.VerifyPassword(password)
 }
 
 // Address 地址
@@ -108,40 +153,120 @@ func (w *Wallet) PublicKey() *ecdsa.PublicKey {
 	return &w.private.PublicKey
 }
 
-func (w *Wallet) initByMnemonic(mnemonic string) error {
-	w.mnemonic = mnemonic
-	pri, err := genKey(mnemonic)
+// Path 密钥派生路径
+func (w *Wallet) Path() string {
+	return w.path
+}
+
+// KeyVersion 密钥派生版本，为 KeyVersionUnknown 表示早期钱包无法由助记词重新生成当前私钥
+func (w *Wallet) KeyVersion() string {
+	return w.version
+}
+
+// Language 助记词语言
+func (w *Wallet) Language() Language {
+	return w.language
+}
+
+// HasPassphrase 是否使用了助记词口令，派生账户时需要再次提供该口令
+func (w *Wallet) HasPassphrase() bool {
+	return w.passphrase
+}
+
+// Recoverable 是否可以由助记词恢复出当前私钥，不保存助记词的钱包同样可以恢复
+func (w *Wallet) Recoverable() bool {
+	return len(w.version) != 0 && w.version != KeyVersionUnknown
+}
+
+// Derive 使用同一助记词派生第 index 个账户并保存为 name，钱包使用了助记词口令时需通过 WithPassphrase 再次提供
+func (w *Wallet) Derive(name string, index uint32, opts ...Option) (*Wallet, error) {
+	if len(w.mnemonic) == 0 {
+		return nil, errors.New("钱包缺少助记词，无法派生账户")
+	}
+	opts = append(opts, WithLanguage(w.language), WithPath(AccountPath(index)), withRoot(w.root))
+	if w.showOnce {
+		opts = append(opts, WithShowOnce())
+	}
+	return RecoverWallet(w.KeyStore, name, w.mnemonic, opts...)
+}
+
+func (w *Wallet) initByMnemonic(mnemonic string, opt *option) error {
+	mnemonic = normalizeMnemonic(mnemonic)
+	lang, err := checkMnemonic(mnemonic, opt.language)
 	if err != nil {
 		return err
 	}
-	w.private = pri
+	w.mnemonic = mnemonic
+	w.language = lang
+	w.passphrase = len(opt.passphrase) != 0
+	w.showOnce = opt.showOnce
+	seed := bip39.NewSeed(mnemonic, opt.passphrase)
+	if w.version == KeyVersionSLIP10 {
+		indexes, err := ParsePath(w.path)
+		if err != nil {
+			return err
+		}
+		master := newMasterKey(seed)
+		w.root = publicToAddress(&master.privateKey().PublicKey)
+		if len(opt.root) != 0 && opt.root != w.root {
+			return ErrPassphrase
+		}
+		w.private = master.derive(indexes).privateKey()
+	} else {
+		pri, err := legacyKey(w.version, seed)
+		if err != nil {
+			return err
+		}
+		w.private = pri
+	}
 	w.addr = genAddr(w.private)
 	if len(w.name) == 0 {
 		w.name = w.addr
 	}
-	err = w.store()
-	if err != nil {
-		return err
-	}
-	return nil
+	return w.store()
 }
 
 // CreateWallet ..
-func CreateWallet(keystore keystore.KeyStore, name string) (*Wallet, error) {
+func CreateWallet(keystore keystore.KeyStore, name string, opts ...Option) (*Wallet, error) {
+	opt := newOption(opts...)
 	w := &Wallet{
 		KeyStore: keystore,
 		name:     name,
+		version:  opt.version,
+		path:     opt.path,
+	}
+	lang := opt.language
+	if len(lang) == 0 {
+		lang = DefaultLanguage
+	}
+	mnemonic, err := genMnemonic(lang, opt.words)
+	if err != nil {
+		return nil, err
 	}
-	mnemonic := genMnemonic()
-	err := w.initByMnemonic(mnemonic)
+	err = w.initByMnemonic(mnemonic, opt)
 	if err != nil {
 		return nil, err
 	}
 	return w, nil
 }
 
-// LoadWallet ..
+// LoadWallet 加载钱包，未记录派生版本的早期钱包在加载时识别并写回派生版本
 func LoadWallet(ks keystore.KeyStore, name string) (*Wallet, error) {
+	w, err := readWallet(ks, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(w.version) == 0 {
+		err = w.migrate()
+		if err != nil {
+			return nil, errors.WithMessagef(err, "迁移账户 %s 失败", name)
+		}
+	}
+	return w, nil
+}
+
+// readWallet 从密钥存储读取钱包，不写入密钥存储
+func readWallet(ks keystore.KeyStore, name string) (*Wallet, error) {
 	opt := &keystore.SecretLoadOpt{
 		Name: name,
 	}
@@ -159,23 +284,55 @@ func LoadWallet(ks keystore.KeyStore, name string) (*Wallet, error) {
 		return nil, err
 	}
 	w := &Wallet{
-		KeyStore: ks,
-		private:  pri,
-		mnemonic: sec.Mnemonic,
-		addr:     genAddr(pri),
-		name:     name,
+		KeyStore:   ks,
+		private:    pri,
+		mnemonic:   sec.Mnemonic,
+		version:    sec.Version,
+		path:       sec.Path,
+		root:       sec.Root,
+		language:   Language(sec.Language),
+		passphrase: sec.Passphrase,
+		addr:       genAddr(pri),
+		name:       name,
 	}
-
 	return w, nil
 }
 
+// migrate 为未记录派生版本的早期钱包识别并记录其派生版本，
+// 识别失败时记录为 KeyVersionUnknown，避免每次加载重复识别，钱包仍可使用但无法由助记词恢复
+func (w *Wallet) migrate() error {
+	if len(w.mnemonic) == 0 {
+		return nil
+	}
+	seed := bip39.NewSeed(w.mnemonic, "")
+	if len(w.path) != 0 {
+		indexes, err := ParsePath(w.path)
+		if err != nil {
+			return err
+		}
+		w.version = KeyVersionSLIP10
+		if newMasterKey(seed).derive(indexes).key.Cmp(w.private.D) != 0 {
+			w.version = KeyVersionUnknown
+		}
+		return w.store()
+	}
+	w.version = detectLegacyVersion(seed, w.private.D)
+	if len(w.version) == 0 {
+		w.version = KeyVersionUnknown
+	}
+	return w.store()
+}
+
 // RecoverWallet ...
-func RecoverWallet(ks keystore.KeyStore, name string, mnemonic string) (*Wallet, error) {
+func RecoverWallet(ks keystore.KeyStore, name string, mnemonic string, opts ...Option) (*Wallet, error) {
+	opt := newOption(opts...)
 	w := &Wallet{
 		KeyStore: ks,
 		name:     name,
+		version:  opt.version,
+		path:     opt.path,
 	}
-	err := w.initByMnemonic(mnemonic)
+	err := w.initByMnemonic(mnemonic, opt)
 	if err != nil {
 		return nil, err
 	}
@@ -204,24 +361,6 @@ func fromECDSAPub(pub *ecdsa.PublicKey) []byte {
 	return elliptic.Marshal(pub.Curve, pub.X, pub.Y)
 }
 
-func genMnemonic() string {
-	bip39.SetWordList(wordlists.ChineseSimplified)
-	entropy, _ := bip39.NewEntropy(128)
-	mnemonic, _ := bip39.NewMnemonic(entropy)
-	return mnemonic
-}
-
-func genKey(mnemonic string) (*ecdsa.PrivateKey, error) {
-	seed := bip39.NewSeed(mnemonic, "")
-	buf := bytes.NewBuffer(seed)
-	pri, err := ecdsa.GenerateKey(Curve, buf) // secp256r1
-	// pri, err := ecdsa.GenerateKey(crypto.S256(), buf) // secp256k1 golang 不支持
-	if err != nil {
-		return nil, err
-	}
-	return pri, nil
-}
-
 func digest(in []byte) []byte {
 	h := sha256.New()
 	h.Write(in)
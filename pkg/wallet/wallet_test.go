@@ -0,0 +1,84 @@
+package wallet
+
+import (
+	"testing"
+
+	"bewallet/pkg/keystore"
+)
+
+func TestCreateAndLoadWallet(t *testing.T) {
+	ks := keystore.NewMemKeyStore()
+	w, err := CreateWallet(ks, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadWallet(ks, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Address() != w.Address() {
+		t.Fatalf("加载的地址 %s 与创建时 %s 不一致", loaded.Address(), w.Address())
+	}
+	if loaded.version != KeyVersionSLIP10 || loaded.path != w.path || loaded.root != w.root {
+		t.Fatalf("派生信息不一致: %+v", loaded)
+	}
+
+	msg := []byte("hello")
+	sig, err := loaded.Sign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ok, err := w.Verify(sig, msg)
+	if err != nil || !ok {
+		t.Fatalf("签名校验失败: %v", err)
+	}
+}
+
+func TestRecoverWallet(t *testing.T) {
+	ks := keystore.NewMemKeyStore()
+	w, err := CreateWallet(ks, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mnemonic, err := w.RevealMnemonic("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := RecoverWallet(keystore.NewMemKeyStore(), "bob", mnemonic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Address() != w.Address() {
+		t.Fatalf("恢复的地址 %s 与原地址 %s 不一致", r.Address(), w.Address())
+	}
+}
+
+func TestLoadWalletMissing(t *testing.T) {
+	_, err := LoadWallet(keystore.NewMemKeyStore(), "missing")
+	if err == nil {
+		t.Fatal("加载不存在的账户应返回错误")
+	}
+}
+
+func TestExportRequiresPassword(t *testing.T) {
+	ks, err := keystore.NewFilKeyStore(t.TempDir(), "Passw0rd!x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := CreateWallet(ks, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.ExportWeb3Key("wrong", "export"); err == nil {
+		t.Fatal("口令错误时不应导出私钥")
+	}
+	if _, err := w.SplitMnemonic("wrong", 3, 2); err == nil {
+		t.Fatal("口令错误时不应拆分助记词")
+	}
+	if _, err := w.ExportWeb3Key("Passw0rd!x", "export"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.SplitMnemonic("Passw0rd!x", 3, 2); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,106 @@
+package wallet
+
+import (
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"bewallet/pkg/keystore"
+)
+
+// keystore v3 JSON 扩展字段 x-meta 中的键
+const (
+	metaCurve      = "curve"
+	metaKeyVersion = "keyVersion"
+	metaPath       = "path"
+	metaRoot       = "root"
+	metaLanguage   = "language"
+	metaPassphrase = "passphrase"
+)
+
+// ExportWeb3Key 校验密钥存储口令 ksPassword 后导出为 Web3 Secret Storage（keystore v3）JSON，使用 password 加密。
+// 私钥为 P-256 曲线，曲线及派生信息记录在扩展字段 x-meta 中，助记词加密保存在 x-mnemonic 中
+func (w *Wallet) ExportWeb3Key(ksPassword, password string) ([]byte, error) {
+	err := w.verifyPassword(ksPassword, "导出私钥")
+	if err != nil {
+		return nil, err
+	}
+	meta := map[string]string{
+		metaCurve: Curve.Params().Name,
+	}
+	if len(w.version) != 0 {
+		meta[metaKeyVersion] = w.version
+	}
+	if len(w.path) != 0 {
+		meta[metaPath] = w.path
+	}
+	if len(w.root) != 0 {
+		meta[metaRoot] = w.root
+	}
+	if len(w.language) != 0 {
+		meta[metaLanguage] = string(w.language)
+	}
+	if w.passphrase {
+		meta[metaPassphrase] = strconv.FormatBool(w.passphrase)
+	}
+	key := &keystore.Web3Key{
+		Address:  w.addr,
+		Key:      w.private.D.FillBytes(make([]byte, 32)),
+		Mnemonic: w.mnemonic,
+		Meta:     meta,
+	}
+	return keystore.EncryptWeb3Key(key, password)
+}
+
+// ImportWeb3Key 从 Web3 Secret Storage（keystore v3）JSON 导入 P-256 私钥并保存为 name 账户，
+// name 为空时以地址为名，账户已存在时返回错误
+func ImportWeb3Key(ks keystore.KeyStore, name string, data []byte, password string) (*Wallet, error) {
+	key, err := keystore.DecryptWeb3Key(data, password)
+	if err != nil {
+		return nil, err
+	}
+	if curve, ok := key.Meta[metaCurve]; ok && curve != Curve.Params().Name {
+		return nil, errors.Errorf("不支持的曲线 %s", curve)
+	}
+	k := new(big.Int).SetBytes(key.Key)
+	if k.Sign() == 0 || k.Cmp(Curve.Params().N) >= 0 {
+		return nil, errors.New("私钥不是有效的 P-256 私钥")
+	}
+	pri := scalarToPrivateKey(k)
+	addr := genAddr(pri)
+	if len(key.Address) != 0 && !strings.EqualFold(strings.TrimPrefix(addr, "0x"), strings.TrimPrefix(key.Address, "0x")) {
+		return nil, errors.Errorf("私钥对应地址 %s 与文件中的地址 %s 不一致，可能不是 P-256 曲线私钥", addr, key.Address)
+	}
+	w := &Wallet{
+		KeyStore: ks,
+		private:  pri,
+		addr:     addr,
+		name:     name,
+	}
+	if len(key.Mnemonic) != 0 {
+		w.mnemonic = key.Mnemonic
+		w.version = key.Meta[metaKeyVersion]
+		w.path = key.Meta[metaPath]
+		w.language = Language(key.Meta[metaLanguage])
+		w.passphrase, _ = strconv.ParseBool(key.Meta[metaPassphrase])
+		w.root = key.Meta[metaRoot]
+	}
+	if len(w.name) == 0 {
+		w.name = w.addr
+	}
+	_, err = ks.Load(&keystore.SecretLoadOpt{Name: w.name})
+	if err == nil {
+		return nil, errors.Errorf("账户 %s 已存在", w.name)
+	}
+	if !os.IsNotExist(errors.Cause(err)) {
+		return nil, err
+	}
+	err = w.store()
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
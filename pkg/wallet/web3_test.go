@@ -0,0 +1,33 @@
+package wallet
+
+import (
+	"testing"
+
+	"bewallet/pkg/keystore"
+)
+
+func TestImportWeb3Key(t *testing.T) {
+	w, err := CreateWallet(keystore.NewMemKeyStore(), "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := w.ExportWeb3Key("", "web3-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ks := keystore.NewMemKeyStore()
+	imported, err := ImportWeb3Key(ks, "bob", data, "web3-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imported.Address() != w.Address() || imported.Path() != w.Path() {
+		t.Fatalf("导入的钱包与原钱包不一致: %s %s", imported.Address(), imported.Path())
+	}
+	if _, err := ImportWeb3Key(ks, "bob", data, "web3-password"); err == nil {
+		t.Fatal("账户已存在时应导入失败")
+	}
+	if _, err := ImportWeb3Key(ks, "carol", data, "wrong"); err == nil {
+		t.Fatal("口令错误时应导入失败")
+	}
+}